@@ -0,0 +1,29 @@
+package v1
+
+// GuardAction is what the controller does when a guard fails
+// +kubebuilder:validation:Enum=Hold;Revert
+type GuardAction string
+
+const (
+	// GuardActionHold keeps the current weight of the target
+	GuardActionHold GuardAction = "Hold"
+	// GuardActionRevert applies the safe value to the target
+	GuardActionRevert GuardAction = "Revert"
+)
+
+// Guard is a condition on a metric which must hold before the policy is
+// allowed to change the target weight
+type Guard struct {
+	// Name identifies the guard in the status and events
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_-]*$`
+	Name string `json:"name"`
+
+	// Metric is the name of the named metrics the guard queries.
+	// spec.metrics is queried when it is not set.
+	// +optional
+	Metric string `json:"metric,omitempty"`
+
+	// Condition is evaluated against the query result, e.g. "result < 0.01".
+	// The result of a vector query is a list, e.g. "all(result, {# < 0.01})".
+	Condition string `json:"condition"`
+}
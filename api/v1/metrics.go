@@ -2,6 +2,9 @@ package v1
 
 import (
 	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // +kubebuilder:object:root=false
@@ -10,7 +13,8 @@ import (
 // +k8s:deepcopy-gen=nil
 
 type Metrics interface {
-	NewClient(ctx context.Context, rebalance Rebalance) (MetricsClient, error)
+	NewClient(ctx context.Context, rebalance Rebalance, c client.Client) (MetricsClient, error)
+	Validate(rebalance Rebalance) error
 }
 
 // +kubebuilder:object:root=false
@@ -22,3 +26,53 @@ type MetricsClient interface {
 	Evaluate(ctx context.Context, expression string) (bool, error)
 	Fetch(ctx context.Context) (float64, error)
 }
+
+// MetricsError is a failure of the metrics data source, as opposed to a
+// failure of the policy which uses it
+// +kubebuilder:object:generate=false
+type MetricsError struct {
+	Err error
+}
+
+func (e *MetricsError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *MetricsError) Unwrap() error {
+	return e.Err
+}
+
+// NewMetricsClient builds the client of spec.metrics. The errors of building
+// the client and of its queries are MetricsErrors.
+func NewMetricsClient(ctx context.Context, r Rebalance, c client.Client) (MetricsClient, error) {
+	m, err := GetMetrics(r)
+	if err != nil {
+		return nil, &MetricsError{fmt.Errorf("failed to get metrics: %w", err)}
+	}
+	mc, err := m.NewClient(ctx, r, c)
+	if err != nil {
+		return nil, &MetricsError{fmt.Errorf("failed to initialize metrics client: %w", err)}
+	}
+	return metricsErrorClient{mc}, nil
+}
+
+// metricsErrorClient wraps the errors of a metrics client in MetricsError
+type metricsErrorClient struct {
+	MetricsClient
+}
+
+func (m metricsErrorClient) Evaluate(ctx context.Context, expression string) (bool, error) {
+	ok, err := m.MetricsClient.Evaluate(ctx, expression)
+	if err != nil {
+		return ok, &MetricsError{err}
+	}
+	return ok, nil
+}
+
+func (m metricsErrorClient) Fetch(ctx context.Context) (float64, error) {
+	v, err := m.MetricsClient.Fetch(ctx)
+	if err != nil {
+		return v, &MetricsError{err}
+	}
+	return v, nil
+}
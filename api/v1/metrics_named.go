@@ -0,0 +1,38 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WithNamedMetrics returns a copy of the rebalance whose spec.metrics is the
+// named metrics, so that the metrics registry builds its client
+func (r Rebalance) WithNamedMetrics(name string) (Rebalance, error) {
+	for _, m := range r.Spec.NamedMetrics {
+		if m.Name == name {
+			nr := *r.DeepCopy()
+			nr.Spec.Metrics = m.Source.DeepCopy()
+			return nr, nil
+		}
+	}
+	return Rebalance{}, fmt.Errorf("named metrics %q is not configured", name)
+}
+
+// MetricsClientFor returns the client of the named metrics, or metrics when name is empty
+func MetricsClientFor(ctx context.Context, r Rebalance, c client.Client, name string, metrics *MetricsClient) (*MetricsClient, error) {
+	if name == "" {
+		return metrics, nil
+	}
+
+	nr, err := r.WithNamedMetrics(name)
+	if err != nil {
+		return nil, err
+	}
+	mc, err := NewMetricsClient(ctx, nr, c)
+	if err != nil {
+		return nil, fmt.Errorf("named metrics %q: %w", name, err)
+	}
+	return &mc, nil
+}
@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithNamedMetrics(t *testing.T) {
+	r := Rebalance{
+		Spec: RebalanceSpec{
+			Metrics: &RebalanceMetrics{Prometheus: &PrometheusMetrics{Query: "rps"}},
+			NamedMetrics: []NamedMetrics{
+				{Name: "latency", Source: RebalanceMetrics{Prometheus: &PrometheusMetrics{Query: "latency"}}},
+			},
+		},
+	}
+
+	nr, err := r.WithNamedMetrics("latency")
+	assert.Nil(t, err)
+	assert.Equal(t, "latency", nr.Spec.Metrics.Prometheus.Query)
+	assert.Equal(t, "rps", r.Spec.Metrics.Prometheus.Query, "the rebalance must not be modified")
+
+	_, err = r.WithNamedMetrics("errors")
+	assert.Error(t, err)
+}
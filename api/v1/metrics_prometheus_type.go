@@ -1,5 +1,8 @@
 package v1
 
+// DefaultPrometheusTimeout is the query timeout in seconds used when the spec does not specify one
+const DefaultPrometheusTimeout = 30
+
 type BasicAuth struct {
 	SecretRef *BasicAuthSecretRef `json:"secretRef,omitempty"`
 }
@@ -21,4 +24,30 @@ type PrometheusMetrics struct {
 
 	// +optional
 	Auth BasicAuth `json:"auth"`
+
+	// BearerToken is sent in the Authorization header.
+	// It can not be used together with basic auth.
+	// +optional
+	BearerToken *SecretKeySelector `json:"bearerTokenSecretRef,omitempty"`
+
+	// +optional
+	TLS *TLSConfig `json:"tls,omitempty"`
+}
+
+type TLSConfig struct {
+	// The CA is the PEM encoded bundle used to verify the server certificate
+	// +optional
+	CA *SecretKeySelector `json:"caSecretRef,omitempty"`
+
+	// The Cert is the PEM encoded client certificate
+	// +optional
+	Cert *SecretKeySelector `json:"certSecretRef,omitempty"`
+
+	// The Key is the PEM encoded private key of the client certificate
+	// +optional
+	Key *SecretKeySelector `json:"keySecretRef,omitempty"`
+
+	// InsecureSkipVerify disables the verification of the server certificate
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
 }
@@ -40,7 +40,10 @@ func GetMetricsByName(name string) (Metrics, bool) {
 
 // Getmetrics returns the metrics from the rebalance
 func GetMetrics(r Rebalance) (Metrics, error) {
-	spec := &r.Spec.Metrics
+	spec := r.Spec.Metrics
+	if spec == nil {
+		return nil, fmt.Errorf("metrics err for %s: metrics is not configured", r.GetName())
+	}
 	metricsName, err := getMetricsName(spec)
 	if err != nil {
 		return nil, fmt.Errorf("metrics err for %s: %w", r.GetName(), err)
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const shouldBeRegisteredMetrics = "metrics should be registered"
@@ -12,10 +13,14 @@ const shouldBeRegisteredMetrics = "metrics should be registered"
 type MT struct{}
 
 // New constructs a SecretsManager Provider.
-func (m *MT) NewClient(ctx context.Context, r Rebalance) (MetricsClient, error) {
+func (m *MT) NewClient(ctx context.Context, r Rebalance, c client.Client) (MetricsClient, error) {
 	return m, nil
 }
 
+func (m *MT) Validate(r Rebalance) error {
+	return nil
+}
+
 func (m *MT) Evaluate(ctx context.Context, expresion string) (bool, error) {
 	return true, nil
 }
@@ -76,7 +81,7 @@ func runTestMetrics(t *testing.T, name string, metrics *RebalanceMetrics, expPan
 	testMetrics := &MT{}
 	rebalance := &Rebalance{
 		Spec: RebalanceSpec{
-			Metrics: *metrics,
+			Metrics: metrics,
 		},
 	}
 	if expPanic {
@@ -86,7 +91,7 @@ func runTestMetrics(t *testing.T, name string, metrics *RebalanceMetrics, expPan
 			}
 		}()
 	}
-	RegisterMetrics(testMetrics, &rebalance.Spec.Metrics)
+	RegisterMetrics(testMetrics, rebalance.Spec.Metrics)
 	t1, ok := GetMetricsByName(name)
 	assert.True(t, ok, shouldBeRegisteredMetrics)
 	assert.Equal(t, testMetrics, t1)
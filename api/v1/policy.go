@@ -2,6 +2,11 @@ package v1
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // +kubebuilder:object:root=false
@@ -11,7 +16,27 @@ import (
 
 // Policy is a common interface for scaling
 type Policy interface {
-	New(rebalance *Rebalance, target *TargetClient, metrics *MetricsClient) (Estimator, error)
+	New(ctx context.Context, rebalance *Rebalance, c client.Client, target *TargetClient, metrics *MetricsClient) (Estimator, error)
+	Validate(rebalance Rebalance) error
+}
+
+// +kubebuilder:object:root=false
+// +kubebuilder:object:generate:false
+// +k8s:deepcopy-gen:interfaces=nil
+// +k8s:deepcopy-gen=nil
+
+// MetricsRequirement is implemented by policies which may estimate without
+// metrics. Policies which do not implement it always need metrics.
+type MetricsRequirement interface {
+	NeedsMetrics(rebalance Rebalance) bool
+}
+
+// NeedsMetrics reports whether the policy needs a metrics client for the rebalance
+func NeedsMetrics(p Policy, rebalance Rebalance) bool {
+	if m, ok := p.(MetricsRequirement); ok {
+		return m.NeedsMetrics(rebalance)
+	}
+	return true
 }
 
 // +kubebuilder:object:root=false
@@ -19,5 +44,69 @@ type Policy interface {
 // +k8s:deepcopy-gen:interfaces=nil
 // +k8s:deepcopy-gen=nil
 type Estimator interface {
-	Estimate(ctx context.Context) (int64, error)
+	Estimate(ctx context.Context) (Estimation, error)
+}
+
+// +kubebuilder:object:root=false
+// +kubebuilder:object:generate:false
+// +k8s:deepcopy-gen:interfaces=nil
+// +k8s:deepcopy-gen=nil
+
+// Estimation is the estimated value with the steps which led to it
+type Estimation struct {
+	// Value is the estimated weight
+	Value int64
+	// Ideal is the value before the rate limits of the policy, if any limited it
+	Ideal *int64
+	// Metric is the metric value the estimation is based on, if any
+	Metric *float64
+	// Steps are the steps which contributed to the value in order
+	Steps []EstimationStep
+	// Recommendations are the recommendations the policy keeps for the next estimations
+	Recommendations []Recommendation
+	// PIDState is the state the pid policy keeps for the next estimation
+	PIDState *PIDState
+}
+
+// AddStep appends a step which turned input into value
+func (e *Estimation) AddStep(name string, input, value int64, format string, args ...interface{}) {
+	e.Steps = append(e.Steps, EstimationStep{
+		Name:   name,
+		Input:  input,
+		Value:  value,
+		Detail: fmt.Sprintf(format, args...),
+	})
+}
+
+// String explains the estimation, e.g. "targetTracking(metric=3000 targetValue=1000 baseValue=8)=16 -> minimum(minimum=20)=20"
+func (e Estimation) String() string {
+	if len(e.Steps) == 0 {
+		return strconv.FormatInt(e.Value, 10)
+	}
+	steps := make([]string, 0, len(e.Steps))
+	for _, s := range e.Steps {
+		steps = append(steps, s.String())
+	}
+	return strings.Join(steps, " -> ")
+}
+
+// +kubebuilder:object:root=false
+// +kubebuilder:object:generate:false
+// +k8s:deepcopy-gen:interfaces=nil
+// +k8s:deepcopy-gen=nil
+
+// EstimationStep is a step of an estimation which turned Input into Value
+type EstimationStep struct {
+	// Name identifies the step
+	Name string
+	// Input is the value the step started from
+	Input int64
+	// Value is the value the step resulted in
+	Value int64
+	// Detail describes the parameters the step used
+	Detail string
+}
+
+func (s EstimationStep) String() string {
+	return fmt.Sprintf("%s(%s)=%d", s.Name, s.Detail, s.Value)
 }
@@ -0,0 +1,67 @@
+package v1
+
+// Operators of CompositePolicy
+const (
+	CompositeMax         = "Max"
+	CompositeMin         = "Min"
+	CompositeAverage     = "Average"
+	CompositeWeightedSum = "WeightedSum"
+)
+
+// CompositePolicy combines the estimations of several child policies
+type CompositePolicy struct {
+	// Operator combines the values estimated by the children
+	// +kubebuilder:validation:Enum=Max;Min;Average;WeightedSum
+	Operator string `json:"operator"`
+
+	// +kubebuilder:validation:MinItems=1
+	Policies []CompositeChild `json:"policies"`
+}
+
+// CompositeChild is a child policy of a composite policy
+type CompositeChild struct {
+	// Name identifies the child in the explanation of the estimation
+	Name string `json:"name"`
+
+	// Policy is the policy of the child. Composite policies cannot be nested.
+	Policy ChildPolicy `json:"policy"`
+
+	// Metrics is the data source of the child. Defaults to spec.metrics.
+	// +optional
+	Metrics *RebalanceMetrics `json:"metrics,omitempty"`
+
+	// Weight is the decimal factor of the child in WeightedSum. Defaults to "1".
+	// +optional
+	Weight string `json:"weight,omitempty"`
+}
+
+// ChildPolicy has the same policies as RebalancePolicy except composite
+// +kubebuilder:validation:MinProperties=1
+// +kubebuilder:validation:MaxProperties=1
+type ChildPolicy struct {
+	// +optional
+	TargetTracking *TargetTrackingPolicy `json:"targettracking,omitempty"`
+
+	// +optional
+	StepScaling *StepScalingPolicy `json:"stepscaling,omitempty"`
+
+	// +optional
+	PID *PIDPolicy `json:"pid,omitempty"`
+
+	// +optional
+	Schedule *SchedulePolicy `json:"schedule,omitempty"`
+
+	// +optional
+	Expr *ExprPolicy `json:"expr,omitempty"`
+}
+
+// RebalancePolicy returns the child policy as a policy of a Rebalance
+func (c ChildPolicy) RebalancePolicy() RebalancePolicy {
+	return RebalancePolicy{
+		TargetTracking: c.TargetTracking,
+		StepScaling:    c.StepScaling,
+		PID:            c.PID,
+		Schedule:       c.Schedule,
+		Expr:           c.Expr,
+	}
+}
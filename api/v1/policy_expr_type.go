@@ -0,0 +1,22 @@
+package v1
+
+// ExprPolicy computes the weight with an expression, e.g.
+// "ceil(base * max(0, rps / 1000 - 1))".
+//
+// The expression may use the value of spec.metrics as metric, the values of
+// the named metrics and the parameters by their names, the current weight of
+// the target as current, the time as hour, minute and weekday (0 is Sunday),
+// and the functions ceil, floor, round, abs, max and min.
+type ExprPolicy struct {
+	// Expression returns the weight as a number, which is rounded to an integer
+	Expression string `json:"expression"`
+
+	// Parameters are decimal constants available to the expression
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// TimeZone is the IANA time zone of hour, minute and weekday.
+	// Defaults to the local time zone of the controller.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
@@ -0,0 +1,63 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PIDPolicy estimates the weight with a PID controller on the error between
+// the metric and the set point. The weight increases while the metric is
+// above the set point when the gains are positive.
+// Gains and limits are decimal strings like "0.05".
+type PIDPolicy struct {
+	// SetPoint is the metric value the policy keeps the metric at
+	SetPoint int64 `json:"setPoint"`
+
+	// Metric is the name of the named metrics the policy uses. Defaults to spec.metrics.
+	// +optional
+	Metric string `json:"metric,omitempty"`
+
+	// Kp is the proportional gain
+	Kp string `json:"kp"`
+
+	// Ki is the integral gain per second
+	// +optional
+	Ki string `json:"ki,omitempty"`
+
+	// Kd is the derivative gain in seconds
+	// +optional
+	Kd string `json:"kd,omitempty"`
+
+	// Bias is the weight when the error and the integral are zero
+	// +optional
+	Bias int64 `json:"bias,omitempty"`
+
+	// IntegralLimit is the largest absolute value of the integral. The
+	// integral does not grow while the weight is bounded by the minimum or
+	// the maximum.
+	// +optional
+	IntegralLimit string `json:"integralLimit,omitempty"`
+
+	// Minimum is the lowest weight the policy estimates
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Minimum int64 `json:"minimum,omitempty"`
+
+	// Maximum is the highest weight the policy estimates
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Maximum *int64 `json:"maximum,omitempty"`
+}
+
+// PIDState is the state of the PID policy kept between rebalance operations.
+// It is discarded after three intervals without an estimation, and after an
+// override or a suspension.
+type PIDState struct {
+	// Integral is the integral of the error over time
+	Integral string `json:"integral"`
+
+	// LastError is the error of the last estimation
+	LastError string `json:"lastError"`
+
+	// LastTime is the time of the last estimation
+	LastTime metav1.Time `json:"lastTime"`
+}
@@ -0,0 +1,13 @@
+package v1
+
+// SchedulePolicy chooses the weight from time windows without metrics
+type SchedulePolicy struct {
+	// DefaultValue is the weight outside of the windows
+	// +kubebuilder:validation:Minimum=0
+	DefaultValue int64 `json:"defaultValue"`
+
+	// Scheduled are the time windows. When several windows are active,
+	// the first one in the list is used.
+	// +kubebuilder:validation:MinItems=1
+	Scheduled []Scheduled `json:"scheduled"`
+}
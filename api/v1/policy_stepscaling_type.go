@@ -0,0 +1,41 @@
+package v1
+
+// StepScalingPolicy changes the weight by steps depending on how far the
+// metric is from the threshold
+type StepScalingPolicy struct {
+	// Threshold is the metric value the bounds of the steps are relative to
+	Threshold int64 `json:"threshold"`
+
+	// Metric is the name of the named metrics the policy uses. Defaults to spec.metrics.
+	// +optional
+	Metric string `json:"metric,omitempty"`
+
+	// Steps are the metric ranges mapped to a weight or a weight change.
+	// The current weight is kept when no step matches the metric.
+	// +kubebuilder:validation:MinItems=1
+	Steps []ScalingStep `json:"steps"`
+}
+
+// ScalingStep applies Weight or Delta when the difference between the metric
+// and the threshold is within the bounds.
+// Exactly one of Weight and Delta must be set.
+type ScalingStep struct {
+	// LowerBound is the inclusive lower bound relative to the threshold.
+	// The step has no lower bound when it is not set.
+	// +optional
+	LowerBound *int64 `json:"lowerBound,omitempty"`
+
+	// UpperBound is the exclusive upper bound relative to the threshold.
+	// The step has no upper bound when it is not set.
+	// +optional
+	UpperBound *int64 `json:"upperBound,omitempty"`
+
+	// Weight is the absolute weight applied to the target
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Weight *int64 `json:"weight,omitempty"`
+
+	// Delta is added to the current weight of the target
+	// +optional
+	Delta *int64 `json:"delta,omitempty"`
+}
@@ -4,14 +4,104 @@ type TargetTrackingPolicy struct {
 	TargetValue int64 `json:"targetValue"`
 	BaseValue   int64 `json:"baseValue"`
 
+	// Metric is the name of the named metrics the policy uses. Defaults to spec.metrics.
+	// +optional
+	Metric string `json:"metric,omitempty"`
+
 	// +optional
 	DisableScaleIn bool        `json:"disableScaleIn,omitempty"`
 	Scheduled      []Scheduled `json:"scheduled,omitempty"`
 	Minimum        int64       `json:"minimum,omitempty"`
+
+	// Maximum is the highest weight the policy estimates
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Maximum *int64 `json:"maximum,omitempty"`
+
+	// Behavior configures how fast the weight follows the metric
+	// +optional
+	Behavior *ScalingBehavior `json:"behavior,omitempty"`
 }
 
+// ScalingBehavior configures the scaling behavior in both directions.
+// Scaling out increases the weight and scaling in decreases it.
+type ScalingBehavior struct {
+	// +optional
+	ScaleOut *ScalingRules `json:"scaleOut,omitempty"`
+
+	// +optional
+	ScaleIn *ScalingRules `json:"scaleIn,omitempty"`
+}
+
+type ScalingRules struct {
+	// StabilizationWindowSeconds is the number of seconds the past recommendations
+	// are considered. Scaling out uses the lowest recommendation within the window
+	// and scaling in uses the highest one.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3600
+	// +optional
+	StabilizationWindowSeconds int32 `json:"stabilizationWindowSeconds,omitempty"`
+
+	// CooldownSeconds is the number of seconds after the last weight change
+	// during which the weight is not changed in this direction.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=3600
+	// +optional
+	CooldownSeconds int32 `json:"cooldownSeconds,omitempty"`
+
+	// MaxStep is the largest change of the weight in a rebalance operation.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxStep int64 `json:"maxStep,omitempty"`
+
+	// MaxStepPercent is the largest change of the weight in a rebalance operation
+	// in percent of the current weight. The change is at least 1.
+	// When both limits are set, the larger change is allowed.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxStepPercent int32 `json:"maxStepPercent,omitempty"`
+}
+
+// Scheduled is a time window with a weight. A window is either a daily
+// StartTime and EndTime, which may span midnight, or a Cron expression with a
+// Duration.
 type Scheduled struct {
-	StartTime string `json:"startTime"`
-	EndTime   string `json:"endTime"`
-	Value     int64  `json:"value"`
+	// StartTime is the start of the daily window in HH:MM
+	// +optional
+	StartTime string `json:"startTime,omitempty"`
+
+	// EndTime is the end of the daily window in HH:MM. The window ends on the
+	// next day when EndTime is before StartTime.
+	// +optional
+	EndTime string `json:"endTime,omitempty"`
+
+	// DaysOfWeek restricts the daily window to the days it starts on
+	// +optional
+	DaysOfWeek []Weekday `json:"daysOfWeek,omitempty"`
+
+	// Cron is a standard cron expression for the starts of the window, e.g. "0 9 * * 1-5"
+	// +optional
+	Cron string `json:"cron,omitempty"`
+
+	// Duration is the length of the window started by Cron, e.g. "8h"
+	// +optional
+	Duration string `json:"duration,omitempty"`
+
+	// StartDate is the first date in YYYY-MM-DD the window may start on
+	// +optional
+	StartDate string `json:"startDate,omitempty"`
+
+	// EndDate is the last date in YYYY-MM-DD the window may start on
+	// +optional
+	EndDate string `json:"endDate,omitempty"`
+
+	// TimeZone is the IANA time zone of the window, e.g. "Asia/Tokyo".
+	// Defaults to the local time zone of the controller.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	Value int64 `json:"value"`
 }
+
+// +kubebuilder:validation:Enum=Sun;Mon;Tue;Wed;Thu;Fri;Sat
+type Weekday string
@@ -23,6 +23,12 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// DefaultInterval is the interval used when the spec does not specify one
+const DefaultInterval = "1m"
+
+// DefaultHistoryLimit is the number of decisions kept in the status when the spec does not specify one
+const DefaultHistoryLimit = 10
+
 // RebalanceSpec defines the desired state of Rebalance
 type RebalanceSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
@@ -37,13 +43,66 @@ type RebalanceSpec struct {
 	// Used to configure the target. Only one target may be set
 	Target RebalanceTarget `json:"target"`
 
-	// Used to configure the datasource. Only one data source may be set
-	Metrics RebalanceMetrics `json:"metrics"`
+	// Used to configure the datasource. Only one data source may be set.
+	// Policies which do not use metrics do not require it.
+	// +optional
+	Metrics *RebalanceMetrics `json:"metrics,omitempty"`
+
+	// NamedMetrics are additional data sources which policies refer to by name
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	NamedMetrics []NamedMetrics `json:"namedMetrics,omitempty"`
 
 	// DryRun is the flag of dry-run operation.
 	// +kubebuilder:default=false
 	// +optional
 	DryRun bool `json:"dryRun,omitempty"`
+
+	// HistoryLimit is the number of decisions kept in the status.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	HistoryLimit *int32 `json:"historyLimit,omitempty"`
+
+	// Suspend stops the rebalance operation. The target weight is left untouched.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Override pins the target weight to a fixed value instead of the value
+	// estimated by the policy.
+	// +optional
+	Override *WeightOverride `json:"override,omitempty"`
+
+	// Guards must all pass before the policy changes the target weight.
+	// Overrides are not guarded.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Guards []Guard `json:"guards,omitempty"`
+
+	// GuardAction is taken when a guard fails. Hold keeps the current weight
+	// and Revert applies SafeValue.
+	// +kubebuilder:default=Hold
+	// +optional
+	GuardAction GuardAction `json:"guardAction,omitempty"`
+
+	// SafeValue is the weight applied when a guard fails and the guard action is Revert.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SafeValue *int64 `json:"safeValue,omitempty"`
+}
+
+// WeightOverride is a fixed weight which takes precedence over the policy
+type WeightOverride struct {
+	// Value is the weight applied to the target while the override is active.
+	// +kubebuilder:validation:Minimum=0
+	Value int64 `json:"value"`
+
+	// ExpiresAt is the time the control returns to the policy.
+	// The override never expires when it is not set.
+	// +optional
+	ExpiresAt *metav1.Time `json:"expiresAt,omitempty"`
 }
 
 // +kubebuilder:validation:MinProperties=1
@@ -51,6 +110,21 @@ type RebalanceSpec struct {
 type RebalancePolicy struct {
 	// +optional
 	TargetTracking *TargetTrackingPolicy `json:"targettracking,omitempty"`
+
+	// +optional
+	StepScaling *StepScalingPolicy `json:"stepscaling,omitempty"`
+
+	// +optional
+	PID *PIDPolicy `json:"pid,omitempty"`
+
+	// +optional
+	Schedule *SchedulePolicy `json:"schedule,omitempty"`
+
+	// +optional
+	Expr *ExprPolicy `json:"expr,omitempty"`
+
+	// +optional
+	Composite *CompositePolicy `json:"composite,omitempty"`
 }
 
 // +kubebuilder:validation:MinProperties=1
@@ -58,6 +132,21 @@ type RebalancePolicy struct {
 type RebalanceTarget struct {
 	// +optional
 	Route53 *Route53Target `json:"route53,omitempty"`
+
+	// +optional
+	GatewayAPI *GatewayAPITarget `json:"gatewayapi,omitempty"`
+
+	// +optional
+	ALB *ALBTarget `json:"alb,omitempty"`
+
+	// +optional
+	GlobalAccelerator *GlobalAcceleratorTarget `json:"globalaccelerator,omitempty"`
+
+	// +optional
+	Cloudflare *CloudflareTarget `json:"cloudflare,omitempty"`
+
+	// +optional
+	CloudDNS *CloudDNSTarget `json:"clouddns,omitempty"`
 }
 
 // +kubebuilder:validation:MinProperties=1
@@ -67,19 +156,76 @@ type RebalanceMetrics struct {
 	Prometheus *PrometheusMetrics `json:"prometheus,omitempty"`
 }
 
-type RebalanceCondition string
+// NamedMetrics is a data source identified by name
+type NamedMetrics struct {
+	// Name identifies the metrics, e.g. "p99_latency"
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z_][a-zA-Z0-9_]*$`
+	Name string `json:"name"`
+
+	// Source configures the data source. Only one data source may be set
+	Source RebalanceMetrics `json:"source"`
+}
+
+// Modes of Rebalance
+const (
+	// ModePolicy is the mode where the weight is estimated by the policy
+	ModePolicy = "Policy"
+	// ModeOverride is the mode where the weight is pinned by spec.override
+	ModeOverride = "Override"
+	// ModeSuspended is the mode where the weight is not updated
+	ModeSuspended = "Suspended"
+)
+
+// Condition types of Rebalance
+const (
+	// ConditionReady is true when the last rebalance operation succeeded
+	ConditionReady = "Ready"
+	// ConditionMetricsAvailable is false when the metrics could not be fetched
+	ConditionMetricsAvailable = "MetricsAvailable"
+	// ConditionTargetReachable is false when the target weight could not be read or updated
+	ConditionTargetReachable = "TargetReachable"
+	// ConditionInSync is true when the target weight equals the desired weight
+	ConditionInSync = "InSync"
+	// ConditionGuardsPassed is false when a guard blocked the policy
+	ConditionGuardsPassed = "GuardsPassed"
+)
 
+// Condition reasons of Rebalance
 const (
-	RebalanceUnhealthy = RebalanceCondition("Unhealthy")
-	RebalanceError     = RebalanceCondition("Error")
-	RebalanceHealty    = RebalanceCondition("Healty")
+	ReasonReconciled      = "Reconciled"
+	ReasonReconcileFailed = "ReconcileFailed"
+	ReasonInvalidSpec     = "InvalidSpec"
+	ReasonPolicyError     = "PolicyError"
+	ReasonMetricsFetched  = "MetricsFetched"
+	ReasonMetricsError    = "MetricsError"
+	ReasonTargetReached   = "TargetReached"
+	ReasonTargetError     = "TargetError"
+	ReasonInSync          = "InSync"
+	ReasonOutOfSync       = "OutOfSync"
+	ReasonDryRun          = "DryRun"
+	ReasonSuspended       = "Suspended"
+	ReasonGuardsPassed    = "GuardsPassed"
+	ReasonGuardFailed     = "GuardFailed"
 )
 
 // RebalanceStatus defines the observed state of Rebalance
 type RebalanceStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
-	Condition RebalanceCondition `json:"condition"`
+
+	// ObservedGeneration is the generation of the spec the status is based on
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Mode is the mode of the last rebalance operation, one of Policy, Override or Suspended
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// Conditions represent the latest available observations of the Rebalance
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
 	// +optional
 	ActualValue int64 `json:"actualValue"`
@@ -87,15 +233,105 @@ type RebalanceStatus struct {
 	// +optional
 	DesiredValue int64 `json:"desiredValue"`
 
+	// IdealValue is the value the policy estimated before the rate limits.
+	// It differs from DesiredValue while the weight moves gradually.
+	// +optional
+	IdealValue int64 `json:"idealValue"`
+
 	// +optional
 	LastUpdateAt string `json:"lastUpdateAt"`
+
+	// Explanation describes how the policy decided the desired value
+	// +optional
+	Explanation string `json:"explanation,omitempty"`
+
+	// Members reports the weights of every member when the target is a group.
+	// +optional
+	Members []MemberStatus `json:"members,omitempty"`
+
+	// LastScaleTime is the last time the weight of the target was changed
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+
+	// Recommendations are the recent recommendations of the policy used by the stabilization windows
+	// +optional
+	Recommendations []Recommendation `json:"recommendations,omitempty"`
+
+	// PIDState is the state of the pid policy
+	// +optional
+	PIDState *PIDState `json:"pidState,omitempty"`
+
+	// History is the list of the latest decisions which changed the weight, newest first.
+	// +optional
+	History []RebalanceDecision `json:"history,omitempty"`
+}
+
+// Recommendation is a weight recommended by the policy at a time
+type Recommendation struct {
+	Time  metav1.Time `json:"time"`
+	Value int64       `json:"value"`
+}
+
+// RebalanceDecision is a record of a weight change decided by the policy
+type RebalanceDecision struct {
+	Time metav1.Time `json:"time"`
+
+	// MetricValue is the metric value the decision is based on
+	// +optional
+	MetricValue string `json:"metricValue,omitempty"`
+
+	// Steps are the steps which contributed to the decision in order
+	// +optional
+	Steps []DecisionStep `json:"steps,omitempty"`
+
+	// PreviousValue is the weight of the target before the decision
+	PreviousValue int64 `json:"previousValue"`
+
+	// DesiredValue is the weight decided by the policy
+	DesiredValue int64 `json:"desiredValue"`
+
+	// AppliedValue is the weight of the target after the decision
+	AppliedValue int64 `json:"appliedValue"`
+
+	// DryRun is true when the weight was not applied because of dry-run
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// DecisionStep is a step of the policy which turned Input into Value
+type DecisionStep struct {
+	Name string `json:"name"`
+
+	// +optional
+	Input int64 `json:"input"`
+
+	Value int64 `json:"value"`
+
+	// Detail describes the parameters the step used
+	// +optional
+	Detail string `json:"detail,omitempty"`
+}
+
+// MemberStatus defines the observed state of a member of a weight group
+type MemberStatus struct {
+	Identifier string `json:"identifier"`
+
+	// +optional
+	ActualValue int64 `json:"actualValue"`
+
+	// +optional
+	DesiredValue int64 `json:"desiredValue"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Last Update",type="date",JSONPath=".status.lastUpdateAt"
-//+kubebuilder:printcolumn:name="Condition",type="string",JSONPath=".status.condition"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="In Sync",type="string",JSONPath=".status.conditions[?(@.type==\"InSync\")].status"
+//+kubebuilder:printcolumn:name="Mode",type="string",JSONPath=".status.mode"
+//+kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason",priority=10
 //+kubebuilder:printcolumn:name="Dry Run",type="boolean",JSONPath=".spec.dryRun",priority=10
+//+kubebuilder:printcolumn:name="Ideal",type="integer",JSONPath=".status.idealValue",priority=10
 //+kubebuilder:printcolumn:name="Desired",type="integer",JSONPath=".status.desiredValue",priority=10
 //+kubebuilder:printcolumn:name="Actual",type="integer",JSONPath=".status.actualValue",priority=10
 // Rebalance is the Schema for the rebalances API
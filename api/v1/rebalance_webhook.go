@@ -17,7 +17,14 @@ limitations under the License.
 package v1
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/antonmedv/expr/parser"
 	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -42,10 +49,59 @@ var _ webhook.Defaulter = &Rebalance{}
 func (r *Rebalance) Default() {
 	rebalancelog.Info("default", "name", r.Name)
 
-	// TODO(user): fill in your defaulting logic.
+	if r.Spec.Interval == "" {
+		r.Spec.Interval = DefaultInterval
+	}
+
+	if r.Spec.HistoryLimit == nil {
+		limit := int32(DefaultHistoryLimit)
+		r.Spec.HistoryLimit = &limit
+	}
+
+	if r.Spec.Metrics != nil {
+		r.Spec.Metrics.Default()
+	}
+	for i := range r.Spec.NamedMetrics {
+		r.Spec.NamedMetrics[i].Source.Default()
+	}
+
+	if len(r.Spec.Guards) > 0 && r.Spec.GuardAction == "" {
+		r.Spec.GuardAction = GuardActionHold
+	}
+
+	if t := r.Spec.Target.Route53; t != nil {
+		if t.Region == "" {
+			t.Region = DefaultRoute53Region
+		}
+		// Route53 returns fully qualified names
+		if t.Resource.Name != "" && !strings.HasSuffix(t.Resource.Name, ".") {
+			t.Resource.Name = t.Resource.Name + "."
+		}
+	}
+
+	if t := r.Spec.Target.GatewayAPI; t != nil && t.Kind == "" {
+		t.Kind = GatewayAPIHTTPRoute
+	}
+
+	if t := r.Spec.Target.GlobalAccelerator; t != nil && t.Mode == "" {
+		t.Mode = GlobalAcceleratorTrafficDial
+	}
+
+	// Cloud DNS returns fully qualified names
+	if t := r.Spec.Target.CloudDNS; t != nil && t.Name != "" && !strings.HasSuffix(t.Name, ".") {
+		t.Name = t.Name + "."
+	}
+}
+
+// Default fills the defaults of the data source
+func (m *RebalanceMetrics) Default() {
+	if p := m.Prometheus; p != nil {
+		if p.Timeout == 0 {
+			p.Timeout = DefaultPrometheusTimeout
+		}
+	}
 }
 
-// TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
 //+kubebuilder:webhook:path=/validate-rebalancer-ch1aki-github-io-v1-rebalance,mutating=false,failurePolicy=fail,sideEffects=None,groups=rebalancer.ch1aki.github.io,resources=rebalances,verbs=create;update,versions=v1,name=vrebalance.kb.io,admissionReviewVersions=v1
 
 var _ webhook.Validator = &Rebalance{}
@@ -54,22 +110,124 @@ var _ webhook.Validator = &Rebalance{}
 func (r *Rebalance) ValidateCreate() error {
 	rebalancelog.Info("validate create", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object creation.
-	return nil
+	return r.validate()
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
 func (r *Rebalance) ValidateUpdate(old runtime.Object) error {
 	rebalancelog.Info("validate update", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object update.
-	return nil
+	return r.validate()
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
 func (r *Rebalance) ValidateDelete() error {
 	rebalancelog.Info("validate delete", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object deletion.
 	return nil
 }
+
+// validate checks the spec and asks the configured policy, target and metrics
+// to validate their own settings
+func (r *Rebalance) validate() error {
+	var errs []error
+
+	interval, err := time.ParseDuration(r.Spec.Interval)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid interval %q: %w", r.Spec.Interval, err))
+	} else if interval <= 0 {
+		errs = append(errs, fmt.Errorf("interval must be positive: %q", r.Spec.Interval))
+	}
+
+	needsMetrics := true
+	if p, err := GetPolicy(*r); err != nil {
+		errs = append(errs, err)
+	} else {
+		if err := p.Validate(*r); err != nil {
+			errs = append(errs, fmt.Errorf("invalid policy: %w", err))
+		}
+		needsMetrics = NeedsMetrics(p, *r)
+	}
+
+	if t, err := GetTarget(*r); err != nil {
+		errs = append(errs, err)
+	} else if err := t.Validate(*r); err != nil {
+		errs = append(errs, fmt.Errorf("invalid target: %w", err))
+	}
+
+	// secrets are sent to the data sources and targets chosen by the spec,
+	// so they must not be read from the namespaces of others
+	for _, path := range crossNamespaceSecretRefs(reflect.ValueOf(r.Spec), "spec", r.Namespace) {
+		errs = append(errs, fmt.Errorf("%s must not refer to a secret in another namespace, copy the secret into namespace %s", path, r.Namespace))
+	}
+
+	if err := r.validateGuards(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid guards: %w", err))
+	}
+	for _, g := range r.Spec.Guards {
+		if g.Metric == "" {
+			needsMetrics = true
+		}
+	}
+
+	if r.Spec.Metrics != nil || needsMetrics {
+		if m, err := GetMetrics(*r); err != nil {
+			errs = append(errs, err)
+		} else if err := m.Validate(*r); err != nil {
+			errs = append(errs, fmt.Errorf("invalid metrics: %w", err))
+		}
+	}
+
+	names := make(map[string]bool, len(r.Spec.NamedMetrics))
+	for _, nm := range r.Spec.NamedMetrics {
+		if names[nm.Name] {
+			errs = append(errs, fmt.Errorf("duplicate named metrics %q", nm.Name))
+			continue
+		}
+		names[nm.Name] = true
+
+		nr, _ := r.WithNamedMetrics(nm.Name)
+		if m, err := GetMetrics(nr); err != nil {
+			errs = append(errs, fmt.Errorf("named metrics %q: %w", nm.Name, err))
+		} else if err := m.Validate(nr); err != nil {
+			errs = append(errs, fmt.Errorf("invalid named metrics %q: %w", nm.Name, err))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateGuards checks the guards and the action taken when they fail
+func (r *Rebalance) validateGuards() error {
+	var errs []error
+
+	names := make(map[string]bool, len(r.Spec.Guards))
+	for _, g := range r.Spec.Guards {
+		if names[g.Name] {
+			errs = append(errs, fmt.Errorf("duplicate guard %q", g.Name))
+			continue
+		}
+		names[g.Name] = true
+
+		if g.Metric != "" {
+			if _, err := r.WithNamedMetrics(g.Metric); err != nil {
+				errs = append(errs, fmt.Errorf("guard %q: %w", g.Name, err))
+			}
+		}
+		if _, err := parser.Parse(g.Condition); err != nil {
+			errs = append(errs, fmt.Errorf("guard %q: invalid condition %q: %w", g.Name, g.Condition, err))
+		}
+	}
+
+	switch r.Spec.GuardAction {
+	case "", GuardActionHold:
+	case GuardActionRevert:
+		if r.Spec.SafeValue == nil {
+			errs = append(errs, fmt.Errorf("safeValue must be set when the guard action is %s", GuardActionRevert))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown guard action %q", r.Spec.GuardAction))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
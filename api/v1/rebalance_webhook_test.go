@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefault(t *testing.T) {
+	r := &Rebalance{
+		Spec: RebalanceSpec{
+			Target: RebalanceTarget{
+				Route53: &Route53Target{
+					Resource: Route53TargetRecord{Name: "www.example.com"},
+				},
+			},
+			Metrics: &RebalanceMetrics{
+				Prometheus: &PrometheusMetrics{},
+			},
+		},
+	}
+	r.Default()
+
+	assert.Equal(t, DefaultInterval, r.Spec.Interval)
+	assert.Equal(t, int64(DefaultPrometheusTimeout), r.Spec.Metrics.Prometheus.Timeout)
+	assert.Equal(t, DefaultRoute53Region, r.Spec.Target.Route53.Region)
+	assert.Equal(t, "www.example.com.", r.Spec.Target.Route53.Resource.Name)
+
+	// defaulting must not override the values in the spec
+	r.Spec.Interval = "5m"
+	r.Spec.Metrics.Prometheus.Timeout = 10
+	r.Spec.Target.Route53.Region = "ap-northeast-1"
+	r.Default()
+
+	assert.Equal(t, "5m", r.Spec.Interval)
+	assert.Equal(t, int64(10), r.Spec.Metrics.Prometheus.Timeout)
+	assert.Equal(t, "ap-northeast-1", r.Spec.Target.Route53.Region)
+	assert.Equal(t, "www.example.com.", r.Spec.Target.Route53.Resource.Name)
+}
+
+func TestValidateGuards(t *testing.T) {
+	safe := int64(0)
+	tests := []struct {
+		name    string
+		guards  []Guard
+		action  GuardAction
+		safe    *int64
+		wantErr bool
+	}{
+		{"valid", []Guard{{Name: "errors", Metric: "errors", Condition: "result < 0.01"}}, GuardActionHold, nil, false},
+		{"spec metrics", []Guard{{Name: "rps", Condition: "result > 0"}}, "", nil, false},
+		{"revert", []Guard{{Name: "errors", Metric: "errors", Condition: "result < 0.01"}}, GuardActionRevert, &safe, false},
+		{"revert without safe value", []Guard{{Name: "errors", Metric: "errors", Condition: "result < 0.01"}}, GuardActionRevert, nil, true},
+		{"unknown metrics", []Guard{{Name: "latency", Metric: "latency", Condition: "result < 1"}}, GuardActionHold, nil, true},
+		{"invalid condition", []Guard{{Name: "errors", Metric: "errors", Condition: "result <"}}, GuardActionHold, nil, true},
+		{
+			"duplicate name",
+			[]Guard{{Name: "errors", Condition: "result < 1"}, {Name: "errors", Condition: "result < 2"}},
+			GuardActionHold, nil, true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Rebalance{
+				Spec: RebalanceSpec{
+					NamedMetrics: []NamedMetrics{
+						{Name: "errors", Source: RebalanceMetrics{Prometheus: &PrometheusMetrics{Query: "errors"}}},
+					},
+					Guards:      tt.guards,
+					GuardAction: tt.action,
+					SafeValue:   tt.safe,
+				},
+			}
+			err := r.validateGuards()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCrossNamespaceSecretRefs(t *testing.T) {
+	own, other := "default", "kube-system"
+	spec := RebalanceSpec{
+		Metrics: &RebalanceMetrics{
+			Prometheus: &PrometheusMetrics{
+				Auth: BasicAuth{SecretRef: &BasicAuthSecretRef{
+					User:     SecretKeySelector{Name: "prometheus", Key: "user", Namespace: &own},
+					Password: SecretKeySelector{Name: "prometheus", Key: "password", Namespace: &other},
+				}},
+			},
+		},
+		NamedMetrics: []NamedMetrics{
+			{Name: "errors", Source: RebalanceMetrics{Prometheus: &PrometheusMetrics{
+				BearerToken: &SecretKeySelector{Name: "token", Key: "token", Namespace: &other},
+			}}},
+		},
+		Target: RebalanceTarget{
+			Cloudflare: &CloudflareTarget{APIToken: SecretKeySelector{Name: "cloudflare", Key: "token"}},
+		},
+	}
+
+	got := crossNamespaceSecretRefs(reflect.ValueOf(spec), "spec", own)
+	want := []string{
+		"spec.metrics.prometheus.auth.secretRef.passwordSecretRef",
+		"spec.namedMetrics[0].source.prometheus.bearerTokenSecretRef",
+	}
+	assert.Equal(t, want, got)
+}
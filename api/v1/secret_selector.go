@@ -1,10 +1,18 @@
 package v1
 
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
 type SecretKeySelector struct {
 	// The name of the Secret resource being referred to.
 	Name string `json:"name,omitempty"`
-	// Namespace of the resource being referred to. Ignored if referent is not cluster-scoped. cluster-scoped defaults
-	// to the namespace of the referent.
+	// Namespace of the resource being referred to. Defaults to the namespace
+	// of the Rebalance.
+	// Deprecated: Secrets in other namespaces are rejected. Copy such a Secret
+	// into the namespace of the Rebalance and remove this field.
 	// +optional
 	Namespace *string `json:"namespace,omitempty"`
 	// The key of the entry in the Secret resource's `data` field to be used. Some instances of this field may be
@@ -31,3 +39,47 @@ func (in *SecretKeySelector) DeepCopy() *SecretKeySelector {
 	in.DeepCopyInto(out)
 	return out
 }
+
+var secretKeySelectorType = reflect.TypeOf(SecretKeySelector{})
+
+// crossNamespaceSecretRefs returns the json paths of the secret key selectors
+// in v which refer to another namespace than namespace
+func crossNamespaceSecretRefs(v reflect.Value, path, namespace string) []string {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return crossNamespaceSecretRefs(v.Elem(), path, namespace)
+	case reflect.Slice, reflect.Array:
+		var paths []string
+		for i := 0; i < v.Len(); i++ {
+			paths = append(paths, crossNamespaceSecretRefs(v.Index(i), fmt.Sprintf("%s[%d]", path, i), namespace)...)
+		}
+		return paths
+	case reflect.Struct:
+		if v.Type() == secretKeySelectorType {
+			if ns := v.Interface().(SecretKeySelector).Namespace; ns != nil && *ns != namespace {
+				return []string{path}
+			}
+			return nil
+		}
+		var paths []string
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name := strings.Split(f.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			paths = append(paths, crossNamespaceSecretRefs(v.Field(i), path+"."+name, namespace)...)
+		}
+		return paths
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 
 type Target interface {
 	NewClient(ctx context.Context, r Rebalance, c client.Client) (TargetClient, error)
+	Validate(r Rebalance) error
 }
 
 // +kubebuilder:object:root=false
@@ -23,4 +24,21 @@ type Target interface {
 type TargetClient interface {
 	GetWeight(ctx context.Context) (int64, error)
 	SetWeight(ctx context.Context, value int64) error
+	// WeightRange returns the lowest and highest weight the target accepts
+	WeightRange() (min int64, max int64)
+}
+
+// +kubebuilder:object:root=false
+// +kubebuilder:object:generate:false
+// +k8s:deepcopy-gen:interfaces=nil
+// +k8s:deepcopy-gen=nil
+
+// GroupTargetClient is implemented by target clients which own a whole
+// weighted set. GetWeight and SetWeight work on the tracked member, while
+// SetWeights applies the weights of all members in a single change.
+type GroupTargetClient interface {
+	TargetClient
+	Distribute(value int64) (map[string]int64, error)
+	GetWeights(ctx context.Context) (map[string]int64, error)
+	SetWeights(ctx context.Context, weights map[string]int64) error
 }
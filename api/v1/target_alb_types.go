@@ -0,0 +1,18 @@
+package v1
+
+// ALBTarget is a target group of the forward action of an ALB listener rule.
+// The weights of the other target groups of the action are left untouched.
+type ALBTarget struct {
+	// RuleARN is the ARN of the listener rule
+	RuleARN string `json:"ruleARN"`
+
+	// TargetGroupARN is the ARN of the target group whose weight is rebalanced
+	TargetGroupARN string `json:"targetGroupARN"`
+
+	// Region defaults to the region of the rule ARN
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// +optional
+	Auth AWSAuth `json:"auth"`
+}
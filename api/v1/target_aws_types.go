@@ -0,0 +1,15 @@
+package v1
+
+// AWSAuth configures the credentials of the AWS targets. The default
+// credential chain is used when no secret is referenced.
+type AWSAuth struct {
+	SecretRef *AWSAuthSecretRef `json:"secretRef,omitempty"`
+}
+
+type AWSAuthSecretRef struct {
+	// The AccessKeyID is used for authentication
+	AccessKeyID SecretKeySelector `json:"accessKeyIDSecretRef,omitempty"`
+
+	// The SecretAccessKey is used for authentication
+	SecretAccessKey SecretKeySelector `json:"secretAccessKeySecretRef,omitempty"`
+}
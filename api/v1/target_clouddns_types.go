@@ -0,0 +1,32 @@
+package v1
+
+// CloudDNSTarget is the weight of an item of the weighted round robin
+// routing policy of a Cloud DNS record set.
+type CloudDNSTarget struct {
+	// Project is the project of the managed zone
+	Project string `json:"project"`
+
+	// ManagedZone is the name of the managed zone
+	ManagedZone string `json:"managedZone"`
+
+	// Name is the name of the record set, e.g. www.example.com.
+	Name string `json:"name"`
+
+	// Type is the type of the record set, e.g. A
+	Type string `json:"type"`
+
+	// Rrdata is one of the rrdatas of the routing policy item whose weight is rebalanced
+	Rrdata string `json:"rrdata"`
+
+	// +optional
+	Auth CloudDNSAuth `json:"auth,omitempty"`
+}
+
+// CloudDNSAuth configures the credentials of the Cloud DNS target. The
+// application default credentials, e.g. workload identity, are used when no
+// service account key is referenced.
+type CloudDNSAuth struct {
+	// ServiceAccountKey is the JSON key of the service account
+	// +optional
+	ServiceAccountKey *SecretKeySelector `json:"serviceAccountKeySecretRef,omitempty"`
+}
@@ -0,0 +1,42 @@
+package v1
+
+// CloudflareTarget is the weight of an origin of a Cloudflare load balancer
+// pool, or the weight of a pool in the random steering of a load balancer.
+// Cloudflare weights range from 0 to 1 and are mapped from weights from 0 to
+// 100, e.g. weight 25 is applied as 0.25.
+type CloudflareTarget struct {
+	// Origin is the origin of a pool whose weight is rebalanced
+	// +optional
+	Origin *CloudflareOrigin `json:"origin,omitempty"`
+
+	// Pool is the pool of a load balancer whose random steering weight is rebalanced
+	// +optional
+	Pool *CloudflarePool `json:"pool,omitempty"`
+
+	// AccountID is the account owning the pools. The pools of the user are
+	// used when it is not set.
+	// +optional
+	AccountID string `json:"accountID,omitempty"`
+
+	// APIToken is the API token with the permission to edit the load balancers
+	APIToken SecretKeySelector `json:"apiTokenSecretRef"`
+}
+
+type CloudflareOrigin struct {
+	// PoolID is the ID of the pool
+	PoolID string `json:"poolID"`
+
+	// Name is the name of the origin in the pool
+	Name string `json:"name"`
+}
+
+type CloudflarePool struct {
+	// ZoneID is the ID of the zone of the load balancer
+	ZoneID string `json:"zoneID"`
+
+	// LoadBalancerID is the ID of the load balancer
+	LoadBalancerID string `json:"loadBalancerID"`
+
+	// PoolID is the ID of the pool in the load balancer
+	PoolID string `json:"poolID"`
+}
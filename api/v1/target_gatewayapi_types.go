@@ -0,0 +1,35 @@
+package v1
+
+// Kinds of the Gateway API routes supported by the gatewayapi target
+const (
+	GatewayAPIHTTPRoute = "HTTPRoute"
+	GatewayAPIGRPCRoute = "GRPCRoute"
+)
+
+// GatewayAPITarget is a backend of a rule of a Gateway API route in the
+// namespace of the Rebalance. Gateway API weights are relative, so the
+// weights of the other backends are left untouched.
+type GatewayAPITarget struct {
+	// Kind is the kind of the route. GRPCRoute is served at v1alpha2 by the
+	// experimental channel of Gateway API v0.6.0 or later.
+	// +kubebuilder:validation:Enum=HTTPRoute;GRPCRoute
+	// +kubebuilder:default=HTTPRoute
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name is the name of the route
+	Name string `json:"name"`
+
+	// Rule is the index of the rule in the route. The first rule is used when
+	// neither rule nor ruleName is set.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Rule *int32 `json:"rule,omitempty"`
+
+	// RuleName is the name of the rule in the route
+	// +optional
+	RuleName string `json:"ruleName,omitempty"`
+
+	// Backend is the name of the backendRef whose weight is rebalanced
+	Backend string `json:"backend"`
+}
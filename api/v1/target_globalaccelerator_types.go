@@ -0,0 +1,31 @@
+package v1
+
+// Modes of the globalaccelerator target
+const (
+	// GlobalAcceleratorTrafficDial rebalances the traffic dial percentage of the endpoint group
+	GlobalAcceleratorTrafficDial = "TrafficDial"
+	// GlobalAcceleratorEndpointWeight rebalances the weight of an endpoint of the endpoint group
+	GlobalAcceleratorEndpointWeight = "EndpointWeight"
+)
+
+// GlobalAcceleratorTarget is the traffic dial of an endpoint group of AWS
+// Global Accelerator, or the weight of one of its endpoints
+type GlobalAcceleratorTarget struct {
+	// EndpointGroupARN is the ARN of the endpoint group
+	EndpointGroupARN string `json:"endpointGroupARN"`
+
+	// Mode is TrafficDial to rebalance the traffic dial percentage (0-100) of
+	// the endpoint group, or EndpointWeight to rebalance the weight (0-255) of
+	// the endpoint
+	// +kubebuilder:validation:Enum=TrafficDial;EndpointWeight
+	// +kubebuilder:default=TrafficDial
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// EndpointID is the ID of the endpoint in the EndpointWeight mode
+	// +optional
+	EndpointID string `json:"endpointID,omitempty"`
+
+	// +optional
+	Auth AWSAuth `json:"auth"`
+}
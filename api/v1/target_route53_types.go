@@ -4,22 +4,15 @@ import (
 	route53Types "github.com/aws/aws-sdk-go-v2/service/route53/types"
 )
 
-type AWSAuth struct {
-	SecretRef *AWSAuthSecretRef `json:"secretRef,omitempty"`
-}
-
-type AWSAuthSecretRef struct {
-	// The AccessKeyID is used for authentication
-	AccessKeyID SecretKeySelector `json:"accessKeyIDSecretRef,omitempty"`
-
-	// The SecretAccessKey is used for authentication
-	SecretAccessKey SecretKeySelector `json:"secretAccessKeySecretRef,omitempty"`
-}
+// DefaultRoute53Region is used when the spec does not specify a region.
+// Route53 is a global service, so any region works.
+const DefaultRoute53Region = "us-east-1"
 
 type Route53TargetRecord struct {
 	Name string              `json:"name"`
 	Type route53Types.RRType `json:"type"`
 
+	// Identifier is ignored when the target has a group.
 	// +optional
 	Identifier string `json:"identifier,omitempty"`
 }
@@ -27,6 +20,12 @@ type Route53TargetRecord struct {
 type Route53Target struct {
 	HostedZoneID string              `json:"hostedZoneID"`
 	Resource     Route53TargetRecord `json:"resource"`
+
+	// Group makes the target own every weighted record of the resource.
+	// The members are identified by their set identifier.
+	// +optional
+	Group *WeightGroup `json:"group,omitempty"`
+
 	// +optional
 	Region string `json:"region"`
 
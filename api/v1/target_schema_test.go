@@ -17,6 +17,10 @@ func (t *TT) NewClient(ctx context.Context, r Rebalance, c client.Client) (Targe
 	return t, nil
 }
 
+func (t *TT) Validate(r Rebalance) error {
+	return nil
+}
+
 func (t *TT) GetWeight(ctx context.Context) (int64, error) {
 	return 0, nil
 }
@@ -25,6 +29,10 @@ func (t *TT) SetWeight(ctx context.Context, value int64) error {
 	return nil
 }
 
+func (t *TT) WeightRange() (int64, int64) {
+	return 0, 100
+}
+
 // TestRegister tests if the Register function
 // (1) panics if it tries to register something invalid
 // (2) stores the correct provider.
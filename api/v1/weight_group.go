@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"fmt"
+	"sort"
+)
+
+// WeightGroup is a set of weighted members which share a total weight.
+// The tracked member receives the value estimated by the policy and the
+// remainder of the total is split between the other members.
+type WeightGroup struct {
+	// Total is the sum of the weights of all members.
+	// +kubebuilder:validation:Minimum=0
+	Total int64 `json:"total"`
+
+	// +kubebuilder:validation:MinItems=2
+	Members []WeightGroupMember `json:"members"`
+}
+
+type WeightGroupMember struct {
+	// Identifier is the identifier of the member in the target.
+	Identifier string `json:"identifier"`
+
+	// Tracked marks the member which receives the estimated value.
+	// Exactly one member must be tracked.
+	// +optional
+	Tracked bool `json:"tracked,omitempty"`
+
+	// Ratio is the share of the remaining weight given to an untracked member.
+	// Members with a zero ratio receive no weight.
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Ratio int64 `json:"ratio,omitempty"`
+}
+
+// TrackedMember returns the member which receives the estimated value.
+func (g *WeightGroup) TrackedMember() (WeightGroupMember, error) {
+	var tracked []WeightGroupMember
+	for _, m := range g.Members {
+		if m.Tracked {
+			tracked = append(tracked, m)
+		}
+	}
+	if len(tracked) != 1 {
+		return WeightGroupMember{}, fmt.Errorf("weight group must have exactly one tracked member, found %d", len(tracked))
+	}
+	return tracked[0], nil
+}
+
+// Distribute splits the total weight of the group between its members.
+// The tracked member receives value, capped to the total, and the remainder is
+// shared by the other members in proportion to their ratio. Rounding uses the
+// largest remainder method so the weights always add up to the total as long
+// as at least one untracked member has a positive ratio.
+func (g *WeightGroup) Distribute(value int64) (map[string]int64, error) {
+	tracked, err := g.TrackedMember()
+	if err != nil {
+		return nil, err
+	}
+
+	if value < 0 {
+		value = 0
+	}
+	if value > g.Total {
+		value = g.Total
+	}
+
+	weights := make(map[string]int64, len(g.Members))
+	weights[tracked.Identifier] = value
+
+	var ratioSum int64
+	for _, m := range g.Members {
+		if !m.Tracked && m.Ratio > 0 {
+			ratioSum += m.Ratio
+		}
+	}
+
+	type share struct {
+		identifier string
+		remainder  int64
+	}
+	remaining := g.Total - value
+	distributed := int64(0)
+	shares := make([]share, 0, len(g.Members))
+	for _, m := range g.Members {
+		if m.Tracked {
+			continue
+		}
+		if m.Ratio <= 0 || ratioSum == 0 {
+			weights[m.Identifier] = 0
+			continue
+		}
+		w := remaining * m.Ratio / ratioSum
+		weights[m.Identifier] = w
+		distributed += w
+		shares = append(shares, share{m.Identifier, remaining * m.Ratio % ratioSum})
+	}
+
+	// hand out what was lost by rounding down, largest remainder first
+	sort.SliceStable(shares, func(i, j int) bool {
+		return shares[i].remainder > shares[j].remainder
+	})
+	for i := 0; distributed < remaining && i < len(shares); i++ {
+		weights[shares[i].identifier]++
+		distributed++
+	}
+
+	return weights, nil
+}
@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightGroupDistribute(t *testing.T) {
+	tbl := []struct {
+		test    string
+		group   WeightGroup
+		value   int64
+		want    map[string]int64
+		wantErr bool
+	}{
+		{
+			test: "should split the remainder evenly",
+			group: WeightGroup{
+				Total: 100,
+				Members: []WeightGroupMember{
+					{Identifier: "aws", Tracked: true},
+					{Identifier: "onprem1", Ratio: 1},
+					{Identifier: "onprem2", Ratio: 1},
+				},
+			},
+			value: 40,
+			want:  map[string]int64{"aws": 40, "onprem1": 30, "onprem2": 30},
+		},
+		{
+			test: "should keep the total when rounding",
+			group: WeightGroup{
+				Total: 10,
+				Members: []WeightGroupMember{
+					{Identifier: "aws", Tracked: true},
+					{Identifier: "onprem1", Ratio: 1},
+					{Identifier: "onprem2", Ratio: 1},
+					{Identifier: "onprem3", Ratio: 1},
+				},
+			},
+			value: 0,
+			want:  map[string]int64{"aws": 0, "onprem1": 4, "onprem2": 3, "onprem3": 3},
+		},
+		{
+			test: "should split by ratio",
+			group: WeightGroup{
+				Total: 100,
+				Members: []WeightGroupMember{
+					{Identifier: "aws", Tracked: true},
+					{Identifier: "onprem1", Ratio: 3},
+					{Identifier: "onprem2", Ratio: 1},
+					{Identifier: "onprem3", Ratio: 0},
+				},
+			},
+			value: 20,
+			want:  map[string]int64{"aws": 20, "onprem1": 60, "onprem2": 20, "onprem3": 0},
+		},
+		{
+			test: "should cap the tracked member to the total",
+			group: WeightGroup{
+				Total: 100,
+				Members: []WeightGroupMember{
+					{Identifier: "aws", Tracked: true},
+					{Identifier: "onprem1", Ratio: 1},
+				},
+			},
+			value: 120,
+			want:  map[string]int64{"aws": 100, "onprem1": 0},
+		},
+		{
+			test: "should fail without a tracked member",
+			group: WeightGroup{
+				Total: 100,
+				Members: []WeightGroupMember{
+					{Identifier: "aws", Ratio: 1},
+					{Identifier: "onprem1", Ratio: 1},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for i := range tbl {
+		row := tbl[i]
+		t.Run(row.test, func(t *testing.T) {
+			got, err := row.group.Distribute(row.value)
+			if row.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, row.want, got)
+		})
+	}
+}
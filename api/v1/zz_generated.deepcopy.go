@@ -22,9 +22,26 @@ limitations under the License.
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ALBTarget) DeepCopyInto(out *ALBTarget) {
+	*out = *in
+	in.Auth.DeepCopyInto(&out.Auth)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ALBTarget.
+func (in *ALBTarget) DeepCopy() *ALBTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ALBTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AWSAuth) DeepCopyInto(out *AWSAuth) {
 	*out = *in
@@ -99,10 +116,348 @@ func (in *BasicAuthSecretRef) DeepCopy() *BasicAuthSecretRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChildPolicy) DeepCopyInto(out *ChildPolicy) {
+	*out = *in
+	if in.TargetTracking != nil {
+		in, out := &in.TargetTracking, &out.TargetTracking
+		*out = new(TargetTrackingPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StepScaling != nil {
+		in, out := &in.StepScaling, &out.StepScaling
+		*out = new(StepScalingPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PID != nil {
+		in, out := &in.PID, &out.PID
+		*out = new(PIDPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(SchedulePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Expr != nil {
+		in, out := &in.Expr, &out.Expr
+		*out = new(ExprPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChildPolicy.
+func (in *ChildPolicy) DeepCopy() *ChildPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ChildPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudDNSAuth) DeepCopyInto(out *CloudDNSAuth) {
+	*out = *in
+	if in.ServiceAccountKey != nil {
+		in, out := &in.ServiceAccountKey, &out.ServiceAccountKey
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudDNSAuth.
+func (in *CloudDNSAuth) DeepCopy() *CloudDNSAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudDNSAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudDNSTarget) DeepCopyInto(out *CloudDNSTarget) {
+	*out = *in
+	in.Auth.DeepCopyInto(&out.Auth)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudDNSTarget.
+func (in *CloudDNSTarget) DeepCopy() *CloudDNSTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudDNSTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudflareOrigin) DeepCopyInto(out *CloudflareOrigin) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudflareOrigin.
+func (in *CloudflareOrigin) DeepCopy() *CloudflareOrigin {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudflareOrigin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudflarePool) DeepCopyInto(out *CloudflarePool) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudflarePool.
+func (in *CloudflarePool) DeepCopy() *CloudflarePool {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudflarePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudflareTarget) DeepCopyInto(out *CloudflareTarget) {
+	*out = *in
+	if in.Origin != nil {
+		in, out := &in.Origin, &out.Origin
+		*out = new(CloudflareOrigin)
+		**out = **in
+	}
+	if in.Pool != nil {
+		in, out := &in.Pool, &out.Pool
+		*out = new(CloudflarePool)
+		**out = **in
+	}
+	in.APIToken.DeepCopyInto(&out.APIToken)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudflareTarget.
+func (in *CloudflareTarget) DeepCopy() *CloudflareTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudflareTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositeChild) DeepCopyInto(out *CompositeChild) {
+	*out = *in
+	in.Policy.DeepCopyInto(&out.Policy)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(RebalanceMetrics)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeChild.
+func (in *CompositeChild) DeepCopy() *CompositeChild {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositeChild)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositePolicy) DeepCopyInto(out *CompositePolicy) {
+	*out = *in
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]CompositeChild, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositePolicy.
+func (in *CompositePolicy) DeepCopy() *CompositePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DecisionStep) DeepCopyInto(out *DecisionStep) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecisionStep.
+func (in *DecisionStep) DeepCopy() *DecisionStep {
+	if in == nil {
+		return nil
+	}
+	out := new(DecisionStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExprPolicy) DeepCopyInto(out *ExprPolicy) {
+	*out = *in
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExprPolicy.
+func (in *ExprPolicy) DeepCopy() *ExprPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ExprPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayAPITarget) DeepCopyInto(out *GatewayAPITarget) {
+	*out = *in
+	if in.Rule != nil {
+		in, out := &in.Rule, &out.Rule
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayAPITarget.
+func (in *GatewayAPITarget) DeepCopy() *GatewayAPITarget {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayAPITarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GlobalAcceleratorTarget) DeepCopyInto(out *GlobalAcceleratorTarget) {
+	*out = *in
+	in.Auth.DeepCopyInto(&out.Auth)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GlobalAcceleratorTarget.
+func (in *GlobalAcceleratorTarget) DeepCopy() *GlobalAcceleratorTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(GlobalAcceleratorTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Guard) DeepCopyInto(out *Guard) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Guard.
+func (in *Guard) DeepCopy() *Guard {
+	if in == nil {
+		return nil
+	}
+	out := new(Guard)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemberStatus) DeepCopyInto(out *MemberStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemberStatus.
+func (in *MemberStatus) DeepCopy() *MemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamedMetrics) DeepCopyInto(out *NamedMetrics) {
+	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamedMetrics.
+func (in *NamedMetrics) DeepCopy() *NamedMetrics {
+	if in == nil {
+		return nil
+	}
+	out := new(NamedMetrics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PIDPolicy) DeepCopyInto(out *PIDPolicy) {
+	*out = *in
+	if in.Maximum != nil {
+		in, out := &in.Maximum, &out.Maximum
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PIDPolicy.
+func (in *PIDPolicy) DeepCopy() *PIDPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PIDPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PIDState) DeepCopyInto(out *PIDState) {
+	*out = *in
+	in.LastTime.DeepCopyInto(&out.LastTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PIDState.
+func (in *PIDState) DeepCopy() *PIDState {
+	if in == nil {
+		return nil
+	}
+	out := new(PIDState)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PrometheusMetrics) DeepCopyInto(out *PrometheusMetrics) {
 	*out = *in
 	in.Auth.DeepCopyInto(&out.Auth)
+	if in.BearerToken != nil {
+		in, out := &in.BearerToken, &out.BearerToken
+		*out = (*in).DeepCopy()
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusMetrics.
@@ -121,7 +476,7 @@ func (in *Rebalance) DeepCopyInto(out *Rebalance) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Rebalance.
@@ -142,6 +497,27 @@ func (in *Rebalance) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RebalanceDecision) DeepCopyInto(out *RebalanceDecision) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]DecisionStep, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalanceDecision.
+func (in *RebalanceDecision) DeepCopy() *RebalanceDecision {
+	if in == nil {
+		return nil
+	}
+	out := new(RebalanceDecision)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RebalanceList) DeepCopyInto(out *RebalanceList) {
 	*out = *in
@@ -202,6 +578,31 @@ func (in *RebalancePolicy) DeepCopyInto(out *RebalancePolicy) {
 		*out = new(TargetTrackingPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.StepScaling != nil {
+		in, out := &in.StepScaling, &out.StepScaling
+		*out = new(StepScalingPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PID != nil {
+		in, out := &in.PID, &out.PID
+		*out = new(PIDPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(SchedulePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Expr != nil {
+		in, out := &in.Expr, &out.Expr
+		*out = new(ExprPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Composite != nil {
+		in, out := &in.Composite, &out.Composite
+		*out = new(CompositePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalancePolicy.
@@ -219,7 +620,38 @@ func (in *RebalanceSpec) DeepCopyInto(out *RebalanceSpec) {
 	*out = *in
 	in.Policy.DeepCopyInto(&out.Policy)
 	in.Target.DeepCopyInto(&out.Target)
-	in.Metrics.DeepCopyInto(&out.Metrics)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(RebalanceMetrics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamedMetrics != nil {
+		in, out := &in.NamedMetrics, &out.NamedMetrics
+		*out = make([]NamedMetrics, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HistoryLimit != nil {
+		in, out := &in.HistoryLimit, &out.HistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Override != nil {
+		in, out := &in.Override, &out.Override
+		*out = new(WeightOverride)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Guards != nil {
+		in, out := &in.Guards, &out.Guards
+		*out = make([]Guard, len(*in))
+		copy(*out, *in)
+	}
+	if in.SafeValue != nil {
+		in, out := &in.SafeValue, &out.SafeValue
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalanceSpec.
@@ -235,6 +667,41 @@ func (in *RebalanceSpec) DeepCopy() *RebalanceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RebalanceStatus) DeepCopyInto(out *RebalanceStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]MemberStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Recommendations != nil {
+		in, out := &in.Recommendations, &out.Recommendations
+		*out = make([]Recommendation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PIDState != nil {
+		in, out := &in.PIDState, &out.PIDState
+		*out = new(PIDState)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]RebalanceDecision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalanceStatus.
@@ -255,6 +722,31 @@ func (in *RebalanceTarget) DeepCopyInto(out *RebalanceTarget) {
 		*out = new(Route53Target)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GatewayAPI != nil {
+		in, out := &in.GatewayAPI, &out.GatewayAPI
+		*out = new(GatewayAPITarget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ALB != nil {
+		in, out := &in.ALB, &out.ALB
+		*out = new(ALBTarget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GlobalAccelerator != nil {
+		in, out := &in.GlobalAccelerator, &out.GlobalAccelerator
+		*out = new(GlobalAcceleratorTarget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cloudflare != nil {
+		in, out := &in.Cloudflare, &out.Cloudflare
+		*out = new(CloudflareTarget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloudDNS != nil {
+		in, out := &in.CloudDNS, &out.CloudDNS
+		*out = new(CloudDNSTarget)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RebalanceTarget.
@@ -267,10 +759,31 @@ func (in *RebalanceTarget) DeepCopy() *RebalanceTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Recommendation) DeepCopyInto(out *Recommendation) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Recommendation.
+func (in *Recommendation) DeepCopy() *Recommendation {
+	if in == nil {
+		return nil
+	}
+	out := new(Recommendation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Route53Target) DeepCopyInto(out *Route53Target) {
 	*out = *in
 	out.Resource = in.Resource
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(WeightGroup)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Auth.DeepCopyInto(&out.Auth)
 }
 
@@ -299,9 +812,111 @@ func (in *Route53TargetRecord) DeepCopy() *Route53TargetRecord {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingBehavior) DeepCopyInto(out *ScalingBehavior) {
+	*out = *in
+	if in.ScaleOut != nil {
+		in, out := &in.ScaleOut, &out.ScaleOut
+		*out = new(ScalingRules)
+		**out = **in
+	}
+	if in.ScaleIn != nil {
+		in, out := &in.ScaleIn, &out.ScaleIn
+		*out = new(ScalingRules)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingBehavior.
+func (in *ScalingBehavior) DeepCopy() *ScalingBehavior {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingBehavior)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingRules) DeepCopyInto(out *ScalingRules) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingRules.
+func (in *ScalingRules) DeepCopy() *ScalingRules {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingStep) DeepCopyInto(out *ScalingStep) {
+	*out = *in
+	if in.LowerBound != nil {
+		in, out := &in.LowerBound, &out.LowerBound
+		*out = new(int64)
+		**out = **in
+	}
+	if in.UpperBound != nil {
+		in, out := &in.UpperBound, &out.UpperBound
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Delta != nil {
+		in, out := &in.Delta, &out.Delta
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingStep.
+func (in *ScalingStep) DeepCopy() *ScalingStep {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulePolicy) DeepCopyInto(out *SchedulePolicy) {
+	*out = *in
+	if in.Scheduled != nil {
+		in, out := &in.Scheduled, &out.Scheduled
+		*out = make([]Scheduled, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulePolicy.
+func (in *SchedulePolicy) DeepCopy() *SchedulePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Scheduled) DeepCopyInto(out *Scheduled) {
 	*out = *in
+	if in.DaysOfWeek != nil {
+		in, out := &in.DaysOfWeek, &out.DaysOfWeek
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Scheduled.
@@ -314,13 +929,74 @@ func (in *Scheduled) DeepCopy() *Scheduled {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepScalingPolicy) DeepCopyInto(out *StepScalingPolicy) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]ScalingStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepScalingPolicy.
+func (in *StepScalingPolicy) DeepCopy() *StepScalingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StepScalingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSConfig) DeepCopyInto(out *TLSConfig) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = (*in).DeepCopy()
+	}
+	if in.Cert != nil {
+		in, out := &in.Cert, &out.Cert
+		*out = (*in).DeepCopy()
+	}
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSConfig.
+func (in *TLSConfig) DeepCopy() *TLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TargetTrackingPolicy) DeepCopyInto(out *TargetTrackingPolicy) {
 	*out = *in
 	if in.Scheduled != nil {
 		in, out := &in.Scheduled, &out.Scheduled
 		*out = make([]Scheduled, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Maximum != nil {
+		in, out := &in.Maximum, &out.Maximum
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(ScalingBehavior)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -333,3 +1009,57 @@ func (in *TargetTrackingPolicy) DeepCopy() *TargetTrackingPolicy {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeightGroup) DeepCopyInto(out *WeightGroup) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]WeightGroupMember, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeightGroup.
+func (in *WeightGroup) DeepCopy() *WeightGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(WeightGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeightGroupMember) DeepCopyInto(out *WeightGroupMember) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeightGroupMember.
+func (in *WeightGroupMember) DeepCopy() *WeightGroupMember {
+	if in == nil {
+		return nil
+	}
+	out := new(WeightGroupMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeightOverride) DeepCopyInto(out *WeightOverride) {
+	*out = *in
+	if in.ExpiresAt != nil {
+		in, out := &in.ExpiresAt, &out.ExpiresAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeightOverride.
+func (in *WeightOverride) DeepCopy() *WeightOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(WeightOverride)
+	in.DeepCopyInto(out)
+	return out
+}
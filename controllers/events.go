@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+// Event reasons of Rebalance
+const (
+	EventReasonWeightChanged = "WeightChanged"
+	EventReasonDryRun        = "DryRun"
+	EventReasonModeChanged   = "ModeChanged"
+	EventReasonWeightClamped = "WeightClamped"
+	EventReasonGuardFailed   = "GuardFailed"
+)
+
+// recordEvents emits the events describing the outcome of a rebalance operation
+func (r *RebalanceReconciler) recordEvents(rb *rebalancerv1.Rebalance, result rebalanceResult, err error) {
+	if r.Recorder == nil {
+		return
+	}
+
+	if modeChanged(rb.Status.Mode, result.mode) {
+		r.Recorder.Event(rb, corev1.EventTypeNormal, EventReasonModeChanged, describeMode(*rb, result.mode))
+	}
+
+	if err != nil {
+		reason := rebalancerv1.ReasonReconcileFailed
+		var rerr *rebalanceError
+		if errors.As(err, &rerr) {
+			reason = rerr.reason
+		}
+		r.Recorder.Event(rb, corev1.EventTypeWarning, reason, err.Error())
+		return
+	}
+
+	if result.clamped {
+		r.Recorder.Eventf(rb, corev1.EventTypeWarning, EventReasonWeightClamped,
+			"estimated weight is out of the range of the target: %s", result.estimation.String())
+	}
+
+	if len(result.guardFailures) > 0 {
+		r.Recorder.Eventf(rb, corev1.EventTypeWarning, EventReasonGuardFailed,
+			"%s: %s", describeGuardFailures(result.guardFailures), result.estimation.String())
+	}
+
+	if !result.drifted {
+		return
+	}
+	change := describeChange(result)
+	if result.changed {
+		r.Recorder.Eventf(rb, corev1.EventTypeNormal, EventReasonWeightChanged, "weight changed %s", change)
+	} else if rb.Spec.DryRun {
+		r.Recorder.Eventf(rb, corev1.EventTypeNormal, EventReasonDryRun, "dry-run: weight would change %s", change)
+	}
+}
+
+// modeChanged reports whether the mode differs from the mode of the previous
+// operation. Starting in the policy mode is not a change.
+func modeChanged(previous, current string) bool {
+	if current == "" {
+		return false
+	}
+	if previous == "" {
+		return current != rebalancerv1.ModePolicy
+	}
+	return previous != current
+}
+
+// describeChange returns a message like
+// "from 10 to 20 (metric value 3000): targetTracking(metric=3000 targetValue=1000 baseValue=10)=20"
+func describeChange(result rebalanceResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "from %d to %d", result.previous, result.desired)
+
+	if len(result.members) > 0 {
+		ms := make([]string, 0, len(result.members))
+		for _, m := range result.members {
+			ms = append(ms, fmt.Sprintf("%s: %d -> %d", m.Identifier, result.previousMembers[m.Identifier], m.DesiredValue))
+		}
+		sort.Strings(ms)
+		fmt.Fprintf(&b, " [%s]", strings.Join(ms, ", "))
+	}
+
+	if m := result.estimation.Metric; m != nil {
+		fmt.Fprintf(&b, " (metric value %s)", strconv.FormatFloat(*m, 'g', -1, 64))
+	}
+	if len(result.estimation.Steps) > 0 {
+		fmt.Fprintf(&b, ": %s", result.estimation.String())
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"testing"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+func TestDescribeChange(t *testing.T) {
+	metric := 1200.5
+
+	tests := []struct {
+		name   string
+		result rebalanceResult
+		want   string
+	}{
+		{
+			"single record",
+			rebalanceResult{previous: 10, desired: 20},
+			"from 10 to 20",
+		},
+		{
+			"with metric value",
+			rebalanceResult{previous: 10, desired: 20, estimation: rebalancerv1.Estimation{Metric: &metric}},
+			"from 10 to 20 (metric value 1200.5)",
+		},
+		{
+			"with explanation",
+			rebalanceResult{
+				previous: 10,
+				desired:  20,
+				estimation: rebalancerv1.Estimation{
+					Value:  20,
+					Metric: &metric,
+					Steps: []rebalancerv1.EstimationStep{
+						{Name: "targetTracking", Value: 3, Detail: "metric=1200.5 targetValue=1000 baseValue=10"},
+						{Name: "minimum", Input: 3, Value: 20, Detail: "minimum=20"},
+					},
+				},
+			},
+			"from 10 to 20 (metric value 1200.5): targetTracking(metric=1200.5 targetValue=1000 baseValue=10)=3 -> minimum(minimum=20)=20",
+		},
+		{
+			"weight group",
+			rebalanceResult{
+				previous: 10,
+				desired:  20,
+				members: []rebalancerv1.MemberStatus{
+					{Identifier: "aws", DesiredValue: 20},
+					{Identifier: "onprem", DesiredValue: 80},
+				},
+				previousMembers: map[string]int64{"aws": 10, "onprem": 90},
+			},
+			"from 10 to 20 [aws: 10 -> 20, onprem: 90 -> 80]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeChange(tt.result); got != tt.want {
+				t.Errorf("describeChange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
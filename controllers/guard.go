@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+// guardFailure is a guard which did not pass
+type guardFailure struct {
+	name    string
+	message string
+}
+
+func (f guardFailure) String() string {
+	return fmt.Sprintf("%s: %s", f.name, f.message)
+}
+
+// checkGuards evaluates every guard and returns the failed ones. A guard
+// whose metrics can not be queried fails, so that an outage of the metrics
+// does not let the policy change the weight unguarded.
+func checkGuards(ctx context.Context, guards []rebalancerv1.Guard, clientFor func(name string) (rebalancerv1.MetricsClient, error)) []guardFailure {
+	var failures []guardFailure
+	for _, g := range guards {
+		mc, err := clientFor(g.Metric)
+		if err != nil {
+			failures = append(failures, guardFailure{g.Name, err.Error()})
+			continue
+		}
+		ok, err := mc.Evaluate(ctx, g.Condition)
+		if err != nil {
+			failures = append(failures, guardFailure{g.Name, fmt.Sprintf("failed to evaluate %q: %s", g.Condition, err)})
+		} else if !ok {
+			failures = append(failures, guardFailure{g.Name, fmt.Sprintf("condition %q is not met", g.Condition)})
+		}
+	}
+	return failures
+}
+
+// guardMetricsClient returns the function which builds the metrics client of
+// a guard, the client of spec.metrics when the name is empty
+func guardMetricsClient(ctx context.Context, rb rebalancerv1.Rebalance, c client.Client) func(name string) (rebalancerv1.MetricsClient, error) {
+	return func(name string) (rebalancerv1.MetricsClient, error) {
+		if name != "" {
+			mc, err := rebalancerv1.MetricsClientFor(ctx, rb, c, name, nil)
+			if err != nil {
+				return nil, err
+			}
+			return *mc, nil
+		}
+		return rebalancerv1.NewMetricsClient(ctx, rb, c)
+	}
+}
+
+// applyGuardAction replaces the estimated weight when a guard failed. It
+// returns true when the weight must be held, i.e. the target is not updated.
+func applyGuardAction(rb rebalancerv1.Rebalance, e *rebalancerv1.Estimation, previous int64, failures []guardFailure) bool {
+	if len(failures) == 0 {
+		return false
+	}
+
+	names := make([]string, 0, len(failures))
+	for _, f := range failures {
+		names = append(names, f.name)
+	}
+	if e.Ideal == nil {
+		ideal := e.Value
+		e.Ideal = &ideal
+	}
+
+	if rb.Spec.GuardAction == rebalancerv1.GuardActionRevert && rb.Spec.SafeValue != nil {
+		e.AddStep("guard", e.Value, *rb.Spec.SafeValue, "failed=%s action=%s", strings.Join(names, ","), rebalancerv1.GuardActionRevert)
+		e.Value = *rb.Spec.SafeValue
+		return false
+	}
+	e.AddStep("guard", e.Value, previous, "failed=%s action=%s", strings.Join(names, ","), rebalancerv1.GuardActionHold)
+	e.Value = previous
+	return true
+}
+
+// describeGuardFailures returns a message like
+// "guard errors: condition \"result < 0.01\" is not met"
+func describeGuardFailures(failures []guardFailure) string {
+	ms := make([]string, 0, len(failures))
+	for _, f := range failures {
+		ms = append(ms, fmt.Sprintf("guard %s", f))
+	}
+	return strings.Join(ms, "; ")
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+// fakeGuardMetrics passes the conditions listed in pass
+type fakeGuardMetrics struct {
+	pass map[string]bool
+	err  error
+}
+
+func (m *fakeGuardMetrics) Fetch(ctx context.Context) (float64, error) {
+	return 0, nil
+}
+
+func (m *fakeGuardMetrics) Evaluate(ctx context.Context, expression string) (bool, error) {
+	return m.pass[expression], m.err
+}
+
+func TestCheckGuards(t *testing.T) {
+	guards := []rebalancerv1.Guard{
+		{Name: "errors", Metric: "errors", Condition: "result < 0.01"},
+		{Name: "rps", Condition: "result > 0"},
+	}
+
+	tests := []struct {
+		name    string
+		metrics map[string]rebalancerv1.MetricsClient
+		want    []string
+	}{
+		{
+			"all passed",
+			map[string]rebalancerv1.MetricsClient{
+				"errors": &fakeGuardMetrics{pass: map[string]bool{"result < 0.01": true}},
+				"":       &fakeGuardMetrics{pass: map[string]bool{"result > 0": true}},
+			},
+			nil,
+		},
+		{
+			"condition not met",
+			map[string]rebalancerv1.MetricsClient{
+				"errors": &fakeGuardMetrics{},
+				"":       &fakeGuardMetrics{pass: map[string]bool{"result > 0": true}},
+			},
+			[]string{"errors"},
+		},
+		{
+			"query failed",
+			map[string]rebalancerv1.MetricsClient{
+				"errors": &fakeGuardMetrics{pass: map[string]bool{"result < 0.01": true}},
+				"":       &fakeGuardMetrics{err: errors.New("timeout")},
+			},
+			[]string{"rps"},
+		},
+		{
+			"metrics unavailable",
+			map[string]rebalancerv1.MetricsClient{},
+			[]string{"errors", "rps"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientFor := func(name string) (rebalancerv1.MetricsClient, error) {
+				mc, ok := tt.metrics[name]
+				if !ok {
+					return nil, errors.New("not configured")
+				}
+				return mc, nil
+			}
+
+			failures := checkGuards(context.Background(), guards, clientFor)
+			if len(failures) != len(tt.want) {
+				t.Fatalf("checkGuards() = %v, want %v", failures, tt.want)
+			}
+			for i, f := range failures {
+				if f.name != tt.want[i] {
+					t.Errorf("checkGuards()[%d] = %v, want %v", i, f.name, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestApplyGuardAction(t *testing.T) {
+	safe := int64(0)
+	failures := []guardFailure{{name: "errors", message: "condition \"result < 0.01\" is not met"}}
+
+	tests := []struct {
+		name     string
+		spec     rebalancerv1.RebalanceSpec
+		failures []guardFailure
+		want     int64
+		wantHeld bool
+	}{
+		{"passed", rebalancerv1.RebalanceSpec{GuardAction: rebalancerv1.GuardActionHold}, nil, 20, false},
+		{"hold", rebalancerv1.RebalanceSpec{GuardAction: rebalancerv1.GuardActionHold}, failures, 10, true},
+		{"hold by default", rebalancerv1.RebalanceSpec{}, failures, 10, true},
+		{"revert", rebalancerv1.RebalanceSpec{GuardAction: rebalancerv1.GuardActionRevert, SafeValue: &safe}, failures, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := rebalancerv1.Estimation{Value: 20}
+			held := applyGuardAction(rebalancerv1.Rebalance{Spec: tt.spec}, &e, 10, tt.failures)
+			if e.Value != tt.want || held != tt.wantHeld {
+				t.Errorf("applyGuardAction() = %v, %v, want %v, %v (%s)", e.Value, held, tt.want, tt.wantHeld, e.String())
+			}
+			if tt.failures != nil && (e.Ideal == nil || *e.Ideal != 20) {
+				t.Errorf("applyGuardAction() must keep the estimated value as the ideal value")
+			}
+		})
+	}
+}
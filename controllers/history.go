@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+// newDecision returns the record of the decision made in a rebalance operation
+func newDecision(rb rebalancerv1.Rebalance, result rebalanceResult) rebalancerv1.RebalanceDecision {
+	d := rebalancerv1.RebalanceDecision{
+		Time:          metav1.Now(),
+		PreviousValue: result.previous,
+		DesiredValue:  result.desired,
+		AppliedValue:  result.actual,
+		DryRun:        rb.Spec.DryRun,
+	}
+	if m := result.estimation.Metric; m != nil {
+		d.MetricValue = strconv.FormatFloat(*m, 'g', -1, 64)
+	}
+	for _, s := range result.estimation.Steps {
+		d.Steps = append(d.Steps, rebalancerv1.DecisionStep{
+			Name:   s.Name,
+			Input:  s.Input,
+			Value:  s.Value,
+			Detail: s.Detail,
+		})
+	}
+	return d
+}
+
+// appendHistory prepends the decision to the history and drops the oldest
+// decisions exceeding the limit
+func appendHistory(history []rebalancerv1.RebalanceDecision, d rebalancerv1.RebalanceDecision, limit int) []rebalancerv1.RebalanceDecision {
+	if limit <= 0 {
+		return nil
+	}
+	history = append([]rebalancerv1.RebalanceDecision{d}, history...)
+	if len(history) > limit {
+		history = history[:limit]
+	}
+	return history
+}
+
+func historyLimit(rb rebalancerv1.Rebalance) int {
+	if rb.Spec.HistoryLimit == nil {
+		return rebalancerv1.DefaultHistoryLimit
+	}
+	return int(*rb.Spec.HistoryLimit)
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"testing"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+func TestAppendHistory(t *testing.T) {
+	history := []rebalancerv1.RebalanceDecision{
+		{DesiredValue: 2},
+		{DesiredValue: 1},
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []int64
+	}{
+		{"within limit", 5, []int64{3, 2, 1}},
+		{"drop oldest", 2, []int64{3, 2}},
+		{"disabled", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := append([]rebalancerv1.RebalanceDecision{}, history...)
+			got := appendHistory(h, rebalancerv1.RebalanceDecision{DesiredValue: 3}, tt.limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("appendHistory() returned %d decisions, want %d", len(got), len(tt.want))
+			}
+			for i, d := range got {
+				if d.DesiredValue != tt.want[i] {
+					t.Errorf("appendHistory()[%d] = %d, want %d", i, d.DesiredValue, tt.want[i])
+				}
+			}
+		})
+	}
+}
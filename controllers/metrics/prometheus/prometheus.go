@@ -2,27 +2,33 @@ package prometheus
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/argoproj/argo-rollouts/utils/evaluate"
 	"github.com/prometheus/client_golang/api"
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"git.pepabo.com/akichan/rebalancer/controllers/secret"
 )
 
 type Metrics struct {
-	api              v1.API
-	queryString      string
-	timeout          time.Duration
-	successCondition string
-	name             string
+	api         v1.API
+	queryString string
+	timeout     time.Duration
+	name        string
 }
 
-func (m *Metrics) NewClient(ctx context.Context, r rebalancerv1.Rebalance) (rebalancerv1.MetricsClient, error) {
+func (m *Metrics) NewClient(ctx context.Context, r rebalancerv1.Rebalance, kube client.Client) (rebalancerv1.MetricsClient, error) {
 	u, err := url.Parse(r.Spec.Metrics.Prometheus.Address)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse url in %s: %w", r.Name, err)
@@ -30,21 +36,141 @@ func (m *Metrics) NewClient(ctx context.Context, r rebalancerv1.Rebalance) (reba
 		return nil, fmt.Errorf("url must contain scheme and host: %w", err)
 	}
 
+	rt, err := roundTripper(ctx, &r, kube)
+	if err != nil {
+		return nil, err
+	}
+
 	c, err := api.NewClient(api.Config{
-		Address: u.String(),
+		Address:      u.String(),
+		RoundTripper: rt,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create prometheus client: %w", err)
 	}
 
+	timeout := r.Spec.Metrics.Prometheus.Timeout
+	if timeout == 0 {
+		timeout = rebalancerv1.DefaultPrometheusTimeout
+	}
+
 	return &Metrics{
 		api:         v1.NewAPI(c),
 		queryString: r.Spec.Metrics.Prometheus.Query,
-		timeout:     time.Duration(r.Spec.Metrics.Prometheus.Timeout) * time.Second,
+		timeout:     time.Duration(timeout) * time.Second,
 		name:        r.Name,
 	}, nil
 }
 
+func (m *Metrics) Validate(r rebalancerv1.Rebalance) error {
+	spec := r.Spec.Metrics.Prometheus
+	var errs []error
+
+	u, err := url.Parse(spec.Address)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to parse address %q: %w", spec.Address, err))
+	} else if !u.IsAbs() || u.Host == "" {
+		errs = append(errs, fmt.Errorf("address must be an absolute url: %q", spec.Address))
+	}
+	if spec.Query == "" {
+		errs = append(errs, fmt.Errorf("query must not be empty"))
+	}
+	if spec.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("timeout must not be negative: %d", spec.Timeout))
+	}
+	if spec.Auth.SecretRef != nil && spec.BearerToken != nil {
+		errs = append(errs, fmt.Errorf("basic auth and bearer token can not be used together"))
+	}
+	if tc := spec.TLS; tc != nil && (tc.Cert == nil) != (tc.Key == nil) {
+		errs = append(errs, fmt.Errorf("client certificate and key must be specified together"))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// roundTripper returns the http.RoundTripper configured with the auth and tls
+// settings of the rebalance
+func roundTripper(ctx context.Context, r *rebalancerv1.Rebalance, c client.Client) (http.RoundTripper, error) {
+	spec := r.Spec.Metrics.Prometheus
+
+	transport := api.DefaultRoundTripper.(*http.Transport).Clone()
+	if spec.TLS != nil {
+		tlsConfig, err := tlsConfigFromSecretRef(ctx, r, c)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	var rt http.RoundTripper = transport
+	if spec.Auth.SecretRef != nil && spec.BearerToken != nil {
+		return nil, fmt.Errorf("basic auth and bearer token can not be used together")
+	}
+	if secRef := spec.Auth.SecretRef; secRef != nil {
+		user, err := secret.GetValue(ctx, c, secRef.User, r.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get basic auth user: %w", err)
+		}
+		password, err := secret.GetValue(ctx, c, secRef.Password, r.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get basic auth password: %w", err)
+		}
+		rt = config.NewBasicAuthRoundTripper(string(user), config.Secret(password), "", rt)
+	}
+	if spec.BearerToken != nil {
+		token, err := secret.GetValue(ctx, c, *spec.BearerToken, r.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bearer token: %w", err)
+		}
+		rt = config.NewAuthorizationCredentialsRoundTripper("Bearer", config.Secret(token), rt)
+	}
+
+	return rt, nil
+}
+
+func tlsConfigFromSecretRef(ctx context.Context, r *rebalancerv1.Rebalance, c client.Client) (*tls.Config, error) {
+	spec := r.Spec.Metrics.Prometheus.TLS
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: spec.InsecureSkipVerify,
+	}
+
+	// custom ca bundle
+	if spec.CA != nil {
+		ca, err := secret.GetValue(ctx, c, *spec.CA, r.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ca bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to parse ca bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	// client certificate
+	if (spec.Cert == nil) != (spec.Key == nil) {
+		return nil, fmt.Errorf("client certificate and key must be specified together")
+	}
+	if spec.Cert != nil {
+		cert, err := secret.GetValue(ctx, c, *spec.Cert, r.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client certificate: %w", err)
+		}
+		key, err := secret.GetValue(ctx, c, *spec.Key, r.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client key: %w", err)
+		}
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	return tlsConfig, nil
+}
+
 func (m *Metrics) Fetch(ctx context.Context) (float64, error) {
 	responce, err := m.query(ctx)
 	if err != nil {
@@ -0,0 +1,152 @@
+package prometheus
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newSecret(data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "prometheus", Namespace: "default"},
+		Data:       data,
+	}
+}
+
+func newRebalance(spec rebalancerv1.PrometheusMetrics) *rebalancerv1.Rebalance {
+	rb := &rebalancerv1.Rebalance{
+		Spec: rebalancerv1.RebalanceSpec{
+			Metrics: &rebalancerv1.RebalanceMetrics{Prometheus: &spec},
+		},
+	}
+	rb.Namespace = "default"
+	return rb
+}
+
+func ref(key string) rebalancerv1.SecretKeySelector {
+	return rebalancerv1.SecretKeySelector{Name: "prometheus", Key: key}
+}
+
+// get sends a request to the server with the round tripper of the rebalance
+func get(t *testing.T, srv *httptest.Server, rb *rebalancerv1.Rebalance, c client.Client) *http.Request {
+	t.Helper()
+	var got *http.Request
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = r })
+
+	rt, err := roundTripper(context.Background(), rb, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: rt}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return got
+}
+
+func TestRoundTripperBasicAuth(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(newSecret(map[string][]byte{
+		"user":     []byte("admin"),
+		"password": []byte("secret"),
+	})).Build()
+	srv := httptest.NewServer(nil)
+	defer srv.Close()
+
+	rb := newRebalance(rebalancerv1.PrometheusMetrics{
+		Auth: rebalancerv1.BasicAuth{SecretRef: &rebalancerv1.BasicAuthSecretRef{User: ref("user"), Password: ref("password")}},
+	})
+	user, password, ok := get(t, srv, rb, c).BasicAuth()
+	if !ok || user != "admin" || password != "secret" {
+		t.Errorf("basic auth = %q, %q, %v, want admin, secret", user, password, ok)
+	}
+}
+
+func TestRoundTripperBearerToken(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(newSecret(map[string][]byte{
+		"token": []byte("t0ken"),
+	})).Build()
+	srv := httptest.NewServer(nil)
+	defer srv.Close()
+
+	token := ref("token")
+	rb := newRebalance(rebalancerv1.PrometheusMetrics{BearerToken: &token})
+	if got := get(t, srv, rb, c).Header.Get("Authorization"); got != "Bearer t0ken" {
+		t.Errorf("Authorization = %q, want Bearer t0ken", got)
+	}
+
+	// secrets in other namespaces must not be sent
+	other := "kube-system"
+	token.Namespace = &other
+	rb = newRebalance(rebalancerv1.PrometheusMetrics{BearerToken: &token})
+	if _, err := roundTripper(context.Background(), rb, c); err == nil {
+		t.Errorf("roundTripper() must fail with a secret in another namespace")
+	}
+}
+
+func TestRoundTripperTLS(t *testing.T) {
+	cert, key := clientCertificate(t)
+	srv := httptest.NewUnstartedServer(nil)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(newSecret(map[string][]byte{
+		"ca":   ca,
+		"cert": cert,
+		"key":  key,
+	})).Build()
+	caRef, certRef, keyRef := ref("ca"), ref("cert"), ref("key")
+	rb := newRebalance(rebalancerv1.PrometheusMetrics{
+		TLS: &rebalancerv1.TLSConfig{CA: &caRef, Cert: &certRef, Key: &keyRef},
+	})
+
+	got := get(t, srv, rb, c)
+	if len(got.TLS.PeerCertificates) != 1 || got.TLS.PeerCertificates[0].Subject.CommonName != "rebalancer" {
+		t.Errorf("client certificate was not presented")
+	}
+}
+
+// clientCertificate returns a PEM encoded self-signed certificate and its key
+func clientCertificate(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "rebalancer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
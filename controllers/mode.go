@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+// activeMode returns the mode the Rebalance is operated in at now
+func activeMode(rb rebalancerv1.Rebalance, now time.Time) string {
+	switch {
+	case rb.Spec.Suspend:
+		return rebalancerv1.ModeSuspended
+	case overrideActive(rb.Spec.Override, now):
+		return rebalancerv1.ModeOverride
+	default:
+		return rebalancerv1.ModePolicy
+	}
+}
+
+func overrideActive(o *rebalancerv1.WeightOverride, now time.Time) bool {
+	return o != nil && (o.ExpiresAt == nil || now.Before(o.ExpiresAt.Time))
+}
+
+// overrideEstimation returns the estimation pinning the weight to the override value
+func overrideEstimation(o *rebalancerv1.WeightOverride) rebalancerv1.Estimation {
+	e := rebalancerv1.Estimation{Value: o.Value}
+	if o.ExpiresAt == nil {
+		e.AddStep("override", 0, o.Value, "expiresAt=never")
+	} else {
+		e.AddStep("override", 0, o.Value, "expiresAt=%s", o.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	return e
+}
+
+// requeueAfter returns the delay until the next rebalance operation, which
+// comes earlier than the interval when an active override expires before it
+func requeueAfter(rb rebalancerv1.Rebalance, mode string, interval time.Duration, now time.Time) time.Duration {
+	if mode != rebalancerv1.ModeOverride || rb.Spec.Override.ExpiresAt == nil {
+		return interval
+	}
+	if d := rb.Spec.Override.ExpiresAt.Sub(now); d < interval {
+		return d
+	}
+	return interval
+}
+
+// describeMode returns a message describing the mode
+func describeMode(rb rebalancerv1.Rebalance, mode string) string {
+	switch mode {
+	case rebalancerv1.ModeSuspended:
+		return "rebalance is suspended"
+	case rebalancerv1.ModeOverride:
+		if rb.Spec.Override.ExpiresAt == nil {
+			return fmt.Sprintf("weight is overridden to %d", rb.Spec.Override.Value)
+		}
+		return fmt.Sprintf("weight is overridden to %d until %s",
+			rb.Spec.Override.Value, rb.Spec.Override.ExpiresAt.UTC().Format(time.RFC3339))
+	default:
+		return "weight is estimated by the policy"
+	}
+}
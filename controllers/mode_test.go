@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+func TestActiveMode(t *testing.T) {
+	now := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	future := metav1.NewTime(now.Add(10 * time.Second))
+	past := metav1.NewTime(now.Add(-10 * time.Second))
+
+	tests := []struct {
+		name         string
+		spec         rebalancerv1.RebalanceSpec
+		wantMode     string
+		wantInterval time.Duration
+	}{
+		{
+			"policy",
+			rebalancerv1.RebalanceSpec{},
+			rebalancerv1.ModePolicy,
+			time.Minute,
+		},
+		{
+			"suspended takes precedence over override",
+			rebalancerv1.RebalanceSpec{Suspend: true, Override: &rebalancerv1.WeightOverride{Value: 10}},
+			rebalancerv1.ModeSuspended,
+			time.Minute,
+		},
+		{
+			"override without expiry",
+			rebalancerv1.RebalanceSpec{Override: &rebalancerv1.WeightOverride{Value: 10}},
+			rebalancerv1.ModeOverride,
+			time.Minute,
+		},
+		{
+			"override requeued at expiry",
+			rebalancerv1.RebalanceSpec{Override: &rebalancerv1.WeightOverride{Value: 10, ExpiresAt: &future}},
+			rebalancerv1.ModeOverride,
+			10 * time.Second,
+		},
+		{
+			"expired override",
+			rebalancerv1.RebalanceSpec{Override: &rebalancerv1.WeightOverride{Value: 10, ExpiresAt: &past}},
+			rebalancerv1.ModePolicy,
+			time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rb := rebalancerv1.Rebalance{Spec: tt.spec}
+			mode := activeMode(rb, now)
+			if mode != tt.wantMode {
+				t.Errorf("activeMode() = %v, want %v", mode, tt.wantMode)
+			}
+			if got := requeueAfter(rb, mode, time.Minute, now); got != tt.wantInterval {
+				t.Errorf("requeueAfter() = %v, want %v", got, tt.wantInterval)
+			}
+		})
+	}
+}
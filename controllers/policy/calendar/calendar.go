@@ -0,0 +1,215 @@
+// Package calendar evaluates the scheduled windows shared by the policies.
+package calendar
+
+import (
+	"fmt"
+	"time"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	clockLayout = "15:04"
+	dateLayout  = "2006-01-02"
+)
+
+var weekdays = map[rebalancev1.Weekday]time.Weekday{
+	"Sun": time.Sunday,
+	"Mon": time.Monday,
+	"Tue": time.Tuesday,
+	"Wed": time.Wednesday,
+	"Thu": time.Thursday,
+	"Fri": time.Friday,
+	"Sat": time.Saturday,
+}
+
+// Window is a parsed scheduled window
+type Window struct {
+	rebalancev1.Scheduled
+
+	loc *time.Location
+
+	// daily window
+	start, end time.Duration
+	days       map[time.Weekday]bool
+
+	// cron window
+	schedule cron.Schedule
+	duration time.Duration
+
+	// dates are the midnights of the start and end dates in loc
+	startDate, endDate *time.Time
+}
+
+// Parse validates the scheduled window
+func Parse(s rebalancev1.Scheduled) (*Window, error) {
+	w := &Window{Scheduled: s, loc: time.Local}
+
+	if s.TimeZone != "" {
+		loc, err := time.LoadLocation(s.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeZone %q: %w", s.TimeZone, err)
+		}
+		w.loc = loc
+	}
+
+	daily := s.StartTime != "" || s.EndTime != ""
+	switch {
+	case daily && s.Cron != "":
+		return nil, fmt.Errorf("startTime and endTime must not be set with cron")
+	case daily:
+		if err := w.parseDaily(); err != nil {
+			return nil, err
+		}
+	case s.Cron != "":
+		if err := w.parseCron(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("either startTime and endTime or cron must be set")
+	}
+
+	var err error
+	if w.startDate, err = w.parseDate("startDate", s.StartDate); err != nil {
+		return nil, err
+	}
+	if w.endDate, err = w.parseDate("endDate", s.EndDate); err != nil {
+		return nil, err
+	}
+	if w.startDate != nil && w.endDate != nil && w.endDate.Before(*w.startDate) {
+		return nil, fmt.Errorf("endDate %s must not be before startDate %s", s.EndDate, s.StartDate)
+	}
+
+	if s.Value < 0 {
+		return nil, fmt.Errorf("value must not be negative: %d", s.Value)
+	}
+	return w, nil
+}
+
+func (w *Window) parseDaily() error {
+	start, err := time.Parse(clockLayout, w.StartTime)
+	if err != nil {
+		return fmt.Errorf("invalid startTime %q, must be HH:MM", w.StartTime)
+	}
+	end, err := time.Parse(clockLayout, w.EndTime)
+	if err != nil {
+		return fmt.Errorf("invalid endTime %q, must be HH:MM", w.EndTime)
+	}
+	if start.Equal(end) {
+		return fmt.Errorf("endTime %s must differ from startTime %s", w.EndTime, w.StartTime)
+	}
+	w.start = time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute
+	w.end = time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute
+
+	if len(w.DaysOfWeek) > 0 {
+		w.days = make(map[time.Weekday]bool, len(w.DaysOfWeek))
+		for _, d := range w.DaysOfWeek {
+			wd, ok := weekdays[d]
+			if !ok {
+				return fmt.Errorf("invalid day of week %q", d)
+			}
+			w.days[wd] = true
+		}
+	}
+	return nil
+}
+
+func (w *Window) parseCron() error {
+	if len(w.DaysOfWeek) > 0 {
+		return fmt.Errorf("daysOfWeek must not be set with cron, use the day of week field instead")
+	}
+	schedule, err := cron.ParseStandard(w.Cron)
+	if err != nil {
+		return fmt.Errorf("invalid cron %q: %w", w.Cron, err)
+	}
+	duration, err := time.ParseDuration(w.Duration)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", w.Duration, err)
+	}
+	if duration <= 0 {
+		return fmt.Errorf("duration must be positive: %q", w.Duration)
+	}
+	w.schedule = schedule
+	w.duration = duration
+	return nil
+}
+
+func (w *Window) parseDate(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	d, err := time.ParseInLocation(dateLayout, value, w.loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q, must be YYYY-MM-DD", name, value)
+	}
+	return &d, nil
+}
+
+// Active reports whether now is within the window
+func (w *Window) Active(now time.Time) bool {
+	now = now.In(w.loc)
+	if w.schedule != nil {
+		// the latest start at or before now
+		start := w.schedule.Next(now.Add(-w.duration))
+		return !start.After(now) && w.startsOn(start)
+	}
+
+	// a window spanning midnight may have started yesterday
+	for _, day := range []time.Time{now, now.AddDate(0, 0, -1)} {
+		midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, w.loc)
+		start := clockOn(midnight, w.start)
+		end := clockOn(midnight, w.end)
+		if w.end < w.start {
+			end = clockOn(midnight.AddDate(0, 0, 1), w.end)
+		}
+		if !now.Before(start) && now.Before(end) && w.startsOn(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// startsOn reports whether the window may start at start
+func (w *Window) startsOn(start time.Time) bool {
+	if w.days != nil && !w.days[start.Weekday()] {
+		return false
+	}
+	date := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, w.loc)
+	if w.startDate != nil && date.Before(*w.startDate) {
+		return false
+	}
+	if w.endDate != nil && date.After(*w.endDate) {
+		return false
+	}
+	return true
+}
+
+// clockOn returns the wall clock time of the day, which follows DST changes
+func clockOn(midnight time.Time, clock time.Duration) time.Time {
+	return time.Date(midnight.Year(), midnight.Month(), midnight.Day(),
+		int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, midnight.Location())
+}
+
+// Active returns the scheduled windows containing now
+func Active(scheduled []rebalancev1.Scheduled, now time.Time) ([]*Window, error) {
+	var active []*Window
+	for _, s := range scheduled {
+		w, err := Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		if w.Active(now) {
+			active = append(active, w)
+		}
+	}
+	return active, nil
+}
+
+// String describes the window like "22:00-06:00" or "0 9 * * 1-5/8h"
+func (w *Window) String() string {
+	if w.schedule != nil {
+		return fmt.Sprintf("%s/%s", w.Cron, w.Duration)
+	}
+	return fmt.Sprintf("%s-%s", w.StartTime, w.EndTime)
+}
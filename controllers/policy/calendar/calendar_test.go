@@ -0,0 +1,152 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+func TestActive(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		scheduled rebalancev1.Scheduled
+		now       time.Time
+		want      bool
+	}{
+		{
+			"overnight window before midnight",
+			rebalancev1.Scheduled{StartTime: "22:00", EndTime: "06:00", TimeZone: "Asia/Tokyo"},
+			time.Date(2022, 12, 20, 23, 0, 0, 0, tokyo),
+			true,
+		},
+		{
+			"overnight window after midnight",
+			rebalancev1.Scheduled{StartTime: "22:00", EndTime: "06:00", TimeZone: "Asia/Tokyo"},
+			time.Date(2022, 12, 21, 5, 59, 0, 0, tokyo),
+			true,
+		},
+		{
+			"overnight window ended",
+			rebalancev1.Scheduled{StartTime: "22:00", EndTime: "06:00", TimeZone: "Asia/Tokyo"},
+			time.Date(2022, 12, 21, 6, 0, 0, 0, tokyo),
+			false,
+		},
+		{
+			"time zone of the window",
+			rebalancev1.Scheduled{StartTime: "09:00", EndTime: "18:00", TimeZone: "Asia/Tokyo"},
+			time.Date(2022, 12, 20, 1, 0, 0, 0, time.UTC),
+			true,
+		},
+		{
+			"weekday",
+			rebalancev1.Scheduled{StartTime: "09:00", EndTime: "18:00", DaysOfWeek: []rebalancev1.Weekday{"Mon", "Tue"}, TimeZone: "Asia/Tokyo"},
+			time.Date(2022, 12, 20, 10, 0, 0, 0, tokyo),
+			true,
+		},
+		{
+			"weekend",
+			rebalancev1.Scheduled{StartTime: "09:00", EndTime: "18:00", DaysOfWeek: []rebalancev1.Weekday{"Mon", "Tue"}, TimeZone: "Asia/Tokyo"},
+			time.Date(2022, 12, 24, 10, 0, 0, 0, tokyo),
+			false,
+		},
+		{
+			"overnight window keeps the day it started on",
+			rebalancev1.Scheduled{StartTime: "22:00", EndTime: "06:00", DaysOfWeek: []rebalancev1.Weekday{"Fri"}, TimeZone: "Asia/Tokyo"},
+			time.Date(2022, 12, 24, 1, 0, 0, 0, tokyo),
+			true,
+		},
+		{
+			"before the start date",
+			rebalancev1.Scheduled{StartTime: "09:00", EndTime: "18:00", StartDate: "2022-12-21", TimeZone: "Asia/Tokyo"},
+			time.Date(2022, 12, 20, 10, 0, 0, 0, tokyo),
+			false,
+		},
+		{
+			"on the end date",
+			rebalancev1.Scheduled{StartTime: "09:00", EndTime: "18:00", EndDate: "2022-12-20", TimeZone: "Asia/Tokyo"},
+			time.Date(2022, 12, 20, 17, 0, 0, 0, tokyo),
+			true,
+		},
+		{
+			"across DST start",
+			rebalancev1.Scheduled{StartTime: "01:00", EndTime: "05:00", TimeZone: "America/New_York"},
+			time.Date(2022, 3, 13, 4, 30, 0, 0, newYork),
+			true,
+		},
+		{
+			"cron window",
+			rebalancev1.Scheduled{Cron: "0 9 * * 1-5", Duration: "8h", TimeZone: "Asia/Tokyo"},
+			time.Date(2022, 12, 20, 16, 59, 0, 0, tokyo),
+			true,
+		},
+		{
+			"cron window ended",
+			rebalancev1.Scheduled{Cron: "0 9 * * 1-5", Duration: "8h", TimeZone: "Asia/Tokyo"},
+			time.Date(2022, 12, 20, 17, 0, 0, 0, tokyo),
+			false,
+		},
+		{
+			"cron window on weekend",
+			rebalancev1.Scheduled{Cron: "0 9 * * 1-5", Duration: "8h", TimeZone: "Asia/Tokyo"},
+			time.Date(2022, 12, 24, 10, 0, 0, 0, tokyo),
+			false,
+		},
+		{
+			"cron window spanning midnight",
+			rebalancev1.Scheduled{Cron: "0 23 * * *", Duration: "2h", TimeZone: "Asia/Tokyo"},
+			time.Date(2022, 12, 21, 0, 30, 0, 0, tokyo),
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := Parse(tt.scheduled)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := w.Active(tt.now); got != tt.want {
+				t.Errorf("Active() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheduled rebalancev1.Scheduled
+		wantErr   bool
+	}{
+		{"daily", rebalancev1.Scheduled{StartTime: "09:00", EndTime: "18:00"}, false},
+		{"cron", rebalancev1.Scheduled{Cron: "0 9 * * *", Duration: "1h"}, false},
+		{"empty", rebalancev1.Scheduled{}, true},
+		{"both daily and cron", rebalancev1.Scheduled{StartTime: "09:00", EndTime: "18:00", Cron: "0 9 * * *", Duration: "1h"}, true},
+		{"missing end time", rebalancev1.Scheduled{StartTime: "09:00"}, true},
+		{"malformed cron", rebalancev1.Scheduled{Cron: "every day", Duration: "1h"}, true},
+		{"cron without duration", rebalancev1.Scheduled{Cron: "0 9 * * *"}, true},
+		{"cron with days of week", rebalancev1.Scheduled{Cron: "0 9 * * *", Duration: "1h", DaysOfWeek: []rebalancev1.Weekday{"Mon"}}, true},
+		{"unknown day of week", rebalancev1.Scheduled{StartTime: "09:00", EndTime: "18:00", DaysOfWeek: []rebalancev1.Weekday{"Monday"}}, true},
+		{"malformed date", rebalancev1.Scheduled{StartTime: "09:00", EndTime: "18:00", StartDate: "12/20"}, true},
+		{"end date before start date", rebalancev1.Scheduled{StartTime: "09:00", EndTime: "18:00", StartDate: "2022-12-20", EndDate: "2022-12-19"}, true},
+		{"negative value", rebalancev1.Scheduled{StartTime: "09:00", EndTime: "18:00", Value: -1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.scheduled); (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
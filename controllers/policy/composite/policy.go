@@ -0,0 +1,206 @@
+package composite
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type Policy struct {
+	operator string
+	children []child
+}
+
+type child struct {
+	name      string
+	weight    float64
+	estimator rebalancev1.Estimator
+}
+
+func (p *Policy) New(ctx context.Context, rebalance *rebalancev1.Rebalance, c client.Client,
+	target *rebalancev1.TargetClient, metrics *rebalancev1.MetricsClient) (rebalancev1.Estimator, error) {
+
+	spec := rebalance.Spec.Policy.Composite
+	children := make([]child, 0, len(spec.Policies))
+	for _, ch := range spec.Policies {
+		crb := childRebalance(*rebalance, ch)
+		policy, err := rebalancev1.GetPolicy(crb)
+		if err != nil {
+			return nil, fmt.Errorf("policy %q: %w", ch.Name, err)
+		}
+
+		// children without their own metrics share the metrics of the rebalance
+		mc := metrics
+		if ch.Metrics != nil && rebalancev1.NeedsMetrics(policy, crb) {
+			mclient, err := rebalancev1.NewMetricsClient(ctx, crb, c)
+			if err != nil {
+				return nil, fmt.Errorf("policy %q: %w", ch.Name, err)
+			}
+			mc = &mclient
+		}
+
+		estimator, err := policy.New(ctx, &crb, c, target, mc)
+		if err != nil {
+			return nil, fmt.Errorf("policy %q: %w", ch.Name, err)
+		}
+		weight, err := parseWeight(ch.Weight)
+		if err != nil {
+			return nil, fmt.Errorf("policy %q: %w", ch.Name, err)
+		}
+		children = append(children, child{name: ch.Name, weight: weight, estimator: estimator})
+	}
+
+	return &Policy{
+		operator: spec.Operator,
+		children: children,
+	}, nil
+}
+
+func (p *Policy) Validate(rebalance rebalancev1.Rebalance) error {
+	spec := rebalance.Spec.Policy.Composite
+	var errs []error
+
+	switch spec.Operator {
+	case rebalancev1.CompositeMax, rebalancev1.CompositeMin, rebalancev1.CompositeAverage, rebalancev1.CompositeWeightedSum:
+	default:
+		errs = append(errs, fmt.Errorf("unknown operator %q", spec.Operator))
+	}
+	if len(spec.Policies) == 0 {
+		errs = append(errs, fmt.Errorf("policies must not be empty"))
+	}
+
+	names := make(map[string]bool, len(spec.Policies))
+	var stateful []string
+	for i, ch := range spec.Policies {
+		if ch.Name == "" {
+			errs = append(errs, fmt.Errorf("policies[%d]: name must not be empty", i))
+		} else if names[ch.Name] {
+			errs = append(errs, fmt.Errorf("policies[%d]: duplicate name %q", i, ch.Name))
+		}
+		names[ch.Name] = true
+
+		if _, err := parseWeight(ch.Weight); err != nil {
+			errs = append(errs, fmt.Errorf("policies[%d]: %w", i, err))
+		}
+
+		crb := childRebalance(rebalance, ch)
+		policy, err := rebalancev1.GetPolicy(crb)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("policies[%d]: %w", i, err))
+			continue
+		}
+		if err := policy.Validate(crb); err != nil {
+			errs = append(errs, fmt.Errorf("policies[%d]: invalid policy: %w", i, err))
+		}
+		// children without their own metrics share spec.metrics, which the webhook validates
+		if ch.Metrics != nil {
+			if m, err := rebalancev1.GetMetrics(crb); err != nil {
+				errs = append(errs, fmt.Errorf("policies[%d]: %w", i, err))
+			} else if err := m.Validate(crb); err != nil {
+				errs = append(errs, fmt.Errorf("policies[%d]: invalid metrics: %w", i, err))
+			}
+		}
+		if keepsState(ch.Policy) {
+			stateful = append(stateful, ch.Name)
+		}
+	}
+
+	// the state of a policy is kept in the status of the rebalance, which has room for one policy
+	if len(stateful) > 1 {
+		errs = append(errs, fmt.Errorf("only one policy may keep a state (pid or targettracking with behavior), found %s",
+			strings.Join(stateful, ", ")))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// NeedsMetrics implements rebalancev1.MetricsRequirement
+func (p *Policy) NeedsMetrics(rebalance rebalancev1.Rebalance) bool {
+	for _, ch := range rebalance.Spec.Policy.Composite.Policies {
+		if ch.Metrics != nil {
+			continue
+		}
+		crb := childRebalance(rebalance, ch)
+		policy, err := rebalancev1.GetPolicy(crb)
+		if err != nil || rebalancev1.NeedsMetrics(policy, crb) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) Estimate(ctx context.Context) (rebalancev1.Estimation, error) {
+	var e rebalancev1.Estimation
+
+	values := make([]string, 0, len(p.children))
+	var val float64
+	for i, ch := range p.children {
+		ce, err := ch.estimator.Estimate(ctx)
+		if err != nil {
+			return e, fmt.Errorf("policy %q: %w", ch.name, err)
+		}
+		for _, s := range ce.Steps {
+			e.AddStep(ch.name+"."+s.Name, s.Input, s.Value, "%s", s.Detail)
+		}
+		if ce.Recommendations != nil {
+			e.Recommendations = ce.Recommendations
+		}
+		if ce.PIDState != nil {
+			e.PIDState = ce.PIDState
+		}
+		values = append(values, fmt.Sprintf("%s=%d", ch.name, ce.Value))
+
+		v := float64(ce.Value)
+		switch {
+		case p.operator == rebalancev1.CompositeMax && (i == 0 || v > val):
+			val = v
+		case p.operator == rebalancev1.CompositeMin && (i == 0 || v < val):
+			val = v
+		case p.operator == rebalancev1.CompositeAverage:
+			val += v / float64(len(p.children))
+		case p.operator == rebalancev1.CompositeWeightedSum:
+			val += v * ch.weight
+		}
+	}
+
+	e.Value = int64(math.Round(val))
+	e.AddStep("composite", 0, e.Value, "operator=%s %s", p.operator, strings.Join(values, " "))
+	return e, nil
+}
+
+// childRebalance returns the rebalance as seen by a child policy
+func childRebalance(rebalance rebalancev1.Rebalance, ch rebalancev1.CompositeChild) rebalancev1.Rebalance {
+	crb := *rebalance.DeepCopy()
+	crb.Spec.Policy = ch.Policy.RebalancePolicy()
+	if ch.Metrics != nil {
+		crb.Spec.Metrics = ch.Metrics.DeepCopy()
+	}
+	return crb
+}
+
+func keepsState(p rebalancev1.ChildPolicy) bool {
+	return p.PID != nil || (p.TargetTracking != nil && p.TargetTracking.Behavior != nil)
+}
+
+func parseWeight(s string) (float64, error) {
+	if s == "" {
+		return 1, nil
+	}
+	w, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(w) || math.IsInf(w, 0) {
+		return 0, fmt.Errorf("invalid weight %q, must be a decimal number", s)
+	}
+	return w, nil
+}
+
+func init() {
+	rebalancev1.RegisterPolicy(&Policy{}, &rebalancev1.RebalancePolicy{
+		Composite: &rebalancev1.CompositePolicy{},
+	})
+}
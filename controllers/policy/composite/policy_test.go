@@ -0,0 +1,138 @@
+package composite
+
+import (
+	"context"
+	"testing"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	_ "git.pepabo.com/akichan/rebalancer/controllers/policy/pid"
+	_ "git.pepabo.com/akichan/rebalancer/controllers/policy/schedule"
+	_ "git.pepabo.com/akichan/rebalancer/controllers/policy/targettracking"
+)
+
+type fakeMetrics struct {
+	value float64
+}
+
+func (m *fakeMetrics) Evaluate(ctx context.Context, expression string) (bool, error) {
+	return true, nil
+}
+
+func (m *fakeMetrics) Fetch(ctx context.Context) (float64, error) {
+	return m.value, nil
+}
+
+func children() []rebalancev1.CompositeChild {
+	return []rebalancev1.CompositeChild{
+		{
+			// 8 * (3000 / 1000 - 1) = 16
+			Name: "rps",
+			Policy: rebalancev1.ChildPolicy{
+				TargetTracking: &rebalancev1.TargetTrackingPolicy{TargetValue: 1000, BaseValue: 8},
+			},
+			Weight: "0.5",
+		},
+		{
+			// outside of the window all day
+			Name: "schedule",
+			Policy: rebalancev1.ChildPolicy{
+				Schedule: &rebalancev1.SchedulePolicy{
+					DefaultValue: 5,
+					Scheduled:    []rebalancev1.Scheduled{{Cron: "0 0 1 1 *", Duration: "1m", StartDate: "2000-01-01", EndDate: "2000-01-01"}},
+				},
+			},
+			Weight: "2",
+		},
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		operator string
+		want     int64
+	}{
+		{rebalancev1.CompositeMax, 16},
+		{rebalancev1.CompositeMin, 5},
+		{rebalancev1.CompositeAverage, 11},
+		{rebalancev1.CompositeWeightedSum, 18},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operator, func(t *testing.T) {
+			rb := &rebalancev1.Rebalance{
+				Spec: rebalancev1.RebalanceSpec{
+					Policy: rebalancev1.RebalancePolicy{
+						Composite: &rebalancev1.CompositePolicy{Operator: tt.operator, Policies: children()},
+					},
+				},
+			}
+			var metrics rebalancev1.MetricsClient = &fakeMetrics{3000}
+			estimator, err := (&Policy{}).New(context.Background(), rb, nil, nil, &metrics)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := estimator.Estimate(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Value != tt.want {
+				t.Errorf("Estimate() = %v, want %v (%s)", got.Value, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(p *rebalancev1.CompositePolicy)
+		wantErr bool
+	}{
+		{"valid", func(p *rebalancev1.CompositePolicy) {}, false},
+		{"unknown operator", func(p *rebalancev1.CompositePolicy) { p.Operator = "Median" }, true},
+		{"duplicate name", func(p *rebalancev1.CompositePolicy) { p.Policies[1].Name = "rps" }, true},
+		{"malformed weight", func(p *rebalancev1.CompositePolicy) { p.Policies[0].Weight = "half" }, true},
+		{"invalid child", func(p *rebalancev1.CompositePolicy) { p.Policies[0].Policy.TargetTracking.TargetValue = 0 }, true},
+		{
+			"several stateful children",
+			func(p *rebalancev1.CompositePolicy) {
+				p.Policies[0].Policy.TargetTracking.Behavior = &rebalancev1.ScalingBehavior{}
+				p.Policies[1].Policy = rebalancev1.ChildPolicy{PID: &rebalancev1.PIDPolicy{Kp: "1"}}
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := rebalancev1.CompositePolicy{Operator: rebalancev1.CompositeMax, Policies: children()}
+			tt.modify(&spec)
+			rb := rebalancev1.Rebalance{
+				Spec: rebalancev1.RebalanceSpec{
+					Policy: rebalancev1.RebalancePolicy{Composite: &spec},
+				},
+			}
+			if err := (&Policy{}).Validate(rb); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNeedsMetrics(t *testing.T) {
+	spec := rebalancev1.CompositePolicy{Operator: rebalancev1.CompositeMax, Policies: children()}
+	rb := rebalancev1.Rebalance{
+		Spec: rebalancev1.RebalanceSpec{
+			Policy: rebalancev1.RebalancePolicy{Composite: &spec},
+		},
+	}
+	if !(&Policy{}).NeedsMetrics(rb) {
+		t.Errorf("NeedsMetrics() = false, want true")
+	}
+
+	spec.Policies = spec.Policies[1:]
+	if (&Policy{}).NeedsMetrics(rb) {
+		t.Errorf("NeedsMetrics() = true, want false")
+	}
+}
@@ -0,0 +1,278 @@
+package expression
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/ast"
+	"github.com/antonmedv/expr/parser"
+	"github.com/antonmedv/expr/vm"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// names of the variables provided by the policy
+const (
+	varMetric  = "metric"
+	varCurrent = "current"
+	varHour    = "hour"
+	varMinute  = "minute"
+	varWeekday = "weekday"
+)
+
+var functions = map[string]interface{}{
+	"ceil":  math.Ceil,
+	"floor": math.Floor,
+	"round": math.Round,
+	"abs":   math.Abs,
+	"max":   math.Max,
+	"min":   math.Min,
+}
+
+type Policy struct {
+	expression string
+	program    *vm.Program
+	params     map[string]float64
+	loc        *time.Location
+	target     *rebalancev1.TargetClient
+	// metrics are the clients of the metrics used by the expression keyed by the variable name
+	metrics    map[string]rebalancev1.MetricsClient
+	useCurrent bool
+	now        func() time.Time
+}
+
+func (p *Policy) New(ctx context.Context, rebalance *rebalancev1.Rebalance, c client.Client,
+	target *rebalancev1.TargetClient, metrics *rebalancev1.MetricsClient) (rebalancev1.Estimator, error) {
+
+	spec := rebalance.Spec.Policy.Expr
+	program, params, loc, err := compile(*rebalance)
+	if err != nil {
+		return nil, err
+	}
+	idents, err := identifiers(spec.Expression)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make(map[string]rebalancev1.MetricsClient)
+	for _, name := range metricNames(*rebalance) {
+		if !idents[name] {
+			continue
+		}
+		mc := metrics
+		if name != varMetric {
+			mc, err = rebalancev1.MetricsClientFor(ctx, *rebalance, c, name, nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if mc == nil || *mc == nil {
+			return nil, fmt.Errorf("metrics client of %q is not available", name)
+		}
+		clients[name] = *mc
+	}
+
+	return &Policy{
+		expression: spec.Expression,
+		program:    program,
+		params:     params,
+		loc:        loc,
+		target:     target,
+		metrics:    clients,
+		useCurrent: idents[varCurrent],
+		now:        time.Now,
+	}, nil
+}
+
+func (p *Policy) Validate(rebalance rebalancev1.Rebalance) error {
+	var errs []error
+
+	reserved := map[string]bool{varCurrent: true, varHour: true, varMinute: true, varWeekday: true}
+	for name := range functions {
+		reserved[name] = true
+	}
+	for _, m := range rebalance.Spec.NamedMetrics {
+		if reserved[m.Name] || m.Name == varMetric {
+			errs = append(errs, fmt.Errorf("named metrics %q shadows a variable of the expression", m.Name))
+		}
+		reserved[m.Name] = true
+	}
+	for name := range rebalance.Spec.Policy.Expr.Parameters {
+		if reserved[name] || name == varMetric {
+			errs = append(errs, fmt.Errorf("parameter %q shadows a variable of the expression", name))
+		}
+	}
+
+	if _, _, _, err := compile(rebalance); err != nil {
+		errs = append(errs, err)
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// NeedsMetrics implements rebalancev1.MetricsRequirement
+func (p *Policy) NeedsMetrics(rebalance rebalancev1.Rebalance) bool {
+	idents, err := identifiers(rebalance.Spec.Policy.Expr.Expression)
+	return err != nil || idents[varMetric]
+}
+
+func (p *Policy) Estimate(ctx context.Context) (rebalancev1.Estimation, error) {
+	var e rebalancev1.Estimation
+
+	env := make(map[string]interface{}, len(functions)+len(p.params)+len(p.metrics)+4)
+	for name, fn := range functions {
+		env[name] = fn
+	}
+	for name, v := range p.params {
+		env[name] = v
+	}
+
+	var vars []string
+	for name, mc := range p.metrics {
+		v, err := mc.Fetch(ctx)
+		if err != nil {
+			return e, fmt.Errorf("failed get current metric %q: %w", name, err)
+		}
+		env[name] = v
+		vars = append(vars, fmt.Sprintf("%s=%s", name, formatFloat(v)))
+		if name == varMetric {
+			e.Metric = &v
+		}
+	}
+
+	if p.useCurrent {
+		current, err := (*p.target).GetWeight(ctx)
+		if err != nil {
+			return e, fmt.Errorf("failed to get current weight: %w", err)
+		}
+		env[varCurrent] = float64(current)
+		vars = append(vars, fmt.Sprintf("%s=%d", varCurrent, current))
+	} else {
+		env[varCurrent] = float64(0)
+	}
+
+	now := p.now().In(p.loc)
+	env[varHour] = float64(now.Hour())
+	env[varMinute] = float64(now.Minute())
+	env[varWeekday] = float64(now.Weekday())
+
+	out, err := expr.Run(p.program, env)
+	if err != nil {
+		return e, fmt.Errorf("failed to evaluate expression: %w", err)
+	}
+	v, ok := out.(float64)
+	if !ok || math.IsNaN(v) || math.IsInf(v, 0) {
+		return e, fmt.Errorf("expression returned %v, not a number", out)
+	}
+
+	val := int64(math.Round(v))
+	if val < 0 {
+		val = 0
+	}
+	sort.Strings(vars)
+	e.AddStep("expr", 0, val, "expression=%q %s", p.expression, strings.Join(vars, " "))
+	e.Value = val
+	return e, nil
+}
+
+// compile type-checks the expression against the variables of the rebalance
+func compile(rebalance rebalancev1.Rebalance) (*vm.Program, map[string]float64, *time.Location, error) {
+	spec := rebalance.Spec.Policy.Expr
+
+	loc := time.Local
+	if spec.TimeZone != "" {
+		l, err := time.LoadLocation(spec.TimeZone)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid timeZone %q: %w", spec.TimeZone, err)
+		}
+		loc = l
+	}
+
+	params := make(map[string]float64, len(spec.Parameters))
+	var errs []error
+	for name, s := range spec.Parameters {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid parameter %s %q, must be a decimal number", name, s))
+			continue
+		}
+		params[name] = v
+	}
+	if len(errs) > 0 {
+		return nil, nil, nil, utilerrors.NewAggregate(errs)
+	}
+
+	env := map[string]interface{}{
+		varCurrent: float64(0),
+		varHour:    float64(0),
+		varMinute:  float64(0),
+		varWeekday: float64(0),
+	}
+	for name, fn := range functions {
+		env[name] = fn
+	}
+	for _, name := range metricNames(rebalance) {
+		env[name] = float64(0)
+	}
+	for name := range params {
+		env[name] = float64(0)
+	}
+
+	program, err := expr.Compile(spec.Expression, expr.Env(env), expr.AsFloat64())
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid expression %q: %w", spec.Expression, err)
+	}
+	return program, params, loc, nil
+}
+
+// metricNames returns the variable names of the metrics of the rebalance
+func metricNames(rebalance rebalancev1.Rebalance) []string {
+	var names []string
+	if rebalance.Spec.Metrics != nil {
+		names = append(names, varMetric)
+	}
+	for _, m := range rebalance.Spec.NamedMetrics {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+// identifiers returns the names the expression refers to
+func identifiers(expression string) (map[string]bool, error) {
+	tree, err := parser.Parse(expression)
+	if err != nil {
+		return nil, err
+	}
+	v := &identifierVisitor{names: make(map[string]bool)}
+	ast.Walk(&tree.Node, v)
+	return v.names, nil
+}
+
+type identifierVisitor struct {
+	names map[string]bool
+}
+
+func (v *identifierVisitor) Enter(node *ast.Node) {}
+
+func (v *identifierVisitor) Exit(node *ast.Node) {
+	if n, ok := (*node).(*ast.IdentifierNode); ok {
+		v.names[n.Value] = true
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func init() {
+	rebalancev1.RegisterPolicy(&Policy{}, &rebalancev1.RebalancePolicy{
+		Expr: &rebalancev1.ExprPolicy{},
+	})
+}
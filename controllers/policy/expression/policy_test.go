@@ -0,0 +1,118 @@
+package expression
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+type fakeMetrics struct {
+	value float64
+}
+
+func (m *fakeMetrics) Evaluate(ctx context.Context, expression string) (bool, error) {
+	return true, nil
+}
+
+func (m *fakeMetrics) Fetch(ctx context.Context) (float64, error) {
+	return m.value, nil
+}
+
+type fakeTarget struct {
+	weight int64
+}
+
+func (t *fakeTarget) GetWeight(ctx context.Context) (int64, error) {
+	return t.weight, nil
+}
+
+func (t *fakeTarget) SetWeight(ctx context.Context, value int64) error {
+	t.weight = value
+	return nil
+}
+
+func (t *fakeTarget) WeightRange() (int64, int64) {
+	return 0, 255
+}
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		want       int64
+	}{
+		{"target tracking", "ceil(base * max(0, metric / 1000 - 1))", 16},
+		{"current weight", "current + 1", 11},
+		{"time of day", "hour >= 9 && hour < 18 ? 100 : 0", 100},
+		{"weekday", "weekday == 2 ? 1 : 0", 1},
+		{"rounded", "2.5", 3},
+		{"not negative", "-5", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rb := &rebalancev1.Rebalance{
+				Spec: rebalancev1.RebalanceSpec{
+					Policy: rebalancev1.RebalancePolicy{
+						Expr: &rebalancev1.ExprPolicy{
+							Expression: tt.expression,
+							Parameters: map[string]string{"base": "8"},
+							TimeZone:   "Asia/Tokyo",
+						},
+					},
+					Metrics: &rebalancev1.RebalanceMetrics{},
+				},
+			}
+			var metrics rebalancev1.MetricsClient = &fakeMetrics{3000}
+			var target rebalancev1.TargetClient = &fakeTarget{10}
+			estimator, err := (&Policy{}).New(context.Background(), rb, nil, &target, &metrics)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Tuesday
+			estimator.(*Policy).now = func() time.Time { return time.Date(2022, 12, 20, 1, 0, 0, 0, time.UTC) }
+
+			got, err := estimator.Estimate(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Value != tt.want {
+				t.Errorf("Estimate() = %v, want %v (%s)", got.Value, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		params     map[string]string
+		wantErr    bool
+	}{
+		{"valid", "ceil(base * max(0, metric / 1000 - 1))", map[string]string{"base": "8"}, false},
+		{"syntax error", "ceil(", nil, true},
+		{"unknown variable", "rps / 1000", nil, true},
+		{"not a number", "metric > 1000", nil, true},
+		{"malformed parameter", "base", map[string]string{"base": "eight"}, true},
+		{"parameter shadows a function", "1", map[string]string{"ceil": "1"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rb := rebalancev1.Rebalance{
+				Spec: rebalancev1.RebalanceSpec{
+					Policy: rebalancev1.RebalancePolicy{
+						Expr: &rebalancev1.ExprPolicy{Expression: tt.expression, Parameters: tt.params},
+					},
+					Metrics: &rebalancev1.RebalanceMetrics{},
+				},
+			}
+			if err := (&Policy{}).Validate(rb); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package pid
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type Policy struct {
+	gains
+	metrics  *rebalancev1.MetricsClient
+	setPoint int64
+	bias     int64
+	minimum  int64
+	maximum  *int64
+	state    *rebalancev1.PIDState
+	// maxGap is the longest time since the last estimation for the state to be used
+	maxGap time.Duration
+	now    func() time.Time
+}
+
+// staleIntervals is the number of rebalance intervals after which the state
+// of the last estimation is discarded
+const staleIntervals = 3
+
+// gains are the decimal parameters of the spec
+type gains struct {
+	kp, ki, kd    float64
+	integralLimit float64
+}
+
+func (p *Policy) New(ctx context.Context, rebalance *rebalancev1.Rebalance, c client.Client,
+	target *rebalancev1.TargetClient, metrics *rebalancev1.MetricsClient) (rebalancev1.Estimator, error) {
+
+	spec := rebalance.Spec.Policy.PID
+	metrics, err := rebalancev1.MetricsClientFor(ctx, *rebalance, c, spec.Metric, metrics)
+	if err != nil {
+		return nil, err
+	}
+	g, err := parseGains(spec)
+	if err != nil {
+		return nil, err
+	}
+	interval := rebalance.Spec.Interval
+	if interval == "" {
+		interval = rebalancev1.DefaultInterval
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interval %q: %w", interval, err)
+	}
+
+	// the state of an estimation before an override or a suspension is stale
+	state := rebalance.Status.PIDState
+	if mode := rebalance.Status.Mode; mode != "" && mode != rebalancev1.ModePolicy {
+		state = nil
+	}
+
+	return &Policy{
+		gains:    g,
+		metrics:  metrics,
+		setPoint: spec.SetPoint,
+		bias:     spec.Bias,
+		minimum:  spec.Minimum,
+		maximum:  spec.Maximum,
+		state:    state,
+		maxGap:   staleIntervals * d,
+		now:      time.Now,
+	}, nil
+}
+
+func (p *Policy) Validate(rebalance rebalancev1.Rebalance) error {
+	spec := rebalance.Spec.Policy.PID
+	var errs []error
+
+	if spec.Metric != "" {
+		if _, err := rebalance.WithNamedMetrics(spec.Metric); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	g, err := parseGains(spec)
+	if err != nil {
+		errs = append(errs, err)
+	} else if g.integralLimit < 0 {
+		errs = append(errs, fmt.Errorf("integralLimit must not be negative: %s", spec.IntegralLimit))
+	}
+	if spec.Minimum < 0 {
+		errs = append(errs, fmt.Errorf("minimum must not be negative: %d", spec.Minimum))
+	}
+	if spec.Maximum != nil && *spec.Maximum < spec.Minimum {
+		errs = append(errs, fmt.Errorf("maximum %d must not be less than minimum %d", *spec.Maximum, spec.Minimum))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// parseGains parses the decimal parameters of the spec. Empty values except kp are zero.
+func parseGains(spec *rebalancev1.PIDPolicy) (gains, error) {
+	var g gains
+	var errs []error
+	for _, f := range []struct {
+		name     string
+		value    string
+		optional bool
+		dst      *float64
+	}{
+		{"kp", spec.Kp, false, &g.kp},
+		{"ki", spec.Ki, true, &g.ki},
+		{"kd", spec.Kd, true, &g.kd},
+		{"integralLimit", spec.IntegralLimit, true, &g.integralLimit},
+	} {
+		if f.value == "" && f.optional {
+			continue
+		}
+		v, err := strconv.ParseFloat(f.value, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			errs = append(errs, fmt.Errorf("invalid %s %q, must be a decimal number", f.name, f.value))
+			continue
+		}
+		*f.dst = v
+	}
+	return g, utilerrors.NewAggregate(errs)
+}
+
+// NeedsMetrics implements rebalancev1.MetricsRequirement
+func (p *Policy) NeedsMetrics(rebalance rebalancev1.Rebalance) bool {
+	return rebalance.Spec.Policy.PID.Metric == ""
+}
+
+func (p *Policy) Estimate(ctx context.Context) (rebalancev1.Estimation, error) {
+	var e rebalancev1.Estimation
+
+	// get current metrics
+	currentMetric, err := (*p.metrics).Fetch(ctx)
+	if err != nil {
+		return e, fmt.Errorf("failed get current metric: %w", err)
+	}
+	e.Metric = &currentMetric
+
+	now := p.now()
+	errVal := currentMetric - float64(p.setPoint)
+	integral, lastErr, dt := p.lastState(now)
+
+	var derivative float64
+	if dt > 0 {
+		derivative = (errVal - lastErr) / dt
+	}
+
+	// the integral does not grow while the output is saturated in the same direction
+	next := integral + errVal*dt
+	if p.integralLimit > 0 {
+		next = math.Max(-p.integralLimit, math.Min(p.integralLimit, next))
+	}
+	out := p.output(errVal, next, derivative)
+	delta := p.ki * (next - integral)
+	windup := (out < float64(p.minimum) && delta < 0) ||
+		(p.maximum != nil && out > float64(*p.maximum) && delta > 0)
+	if windup {
+		out = p.output(errVal, integral, derivative)
+	} else {
+		integral = next
+	}
+
+	e.PIDState = &rebalancev1.PIDState{
+		Integral:  formatFloat(integral),
+		LastError: formatFloat(errVal),
+		LastTime:  metav1.NewTime(now),
+	}
+
+	val := int64(math.Round(out))
+	e.AddStep("pid", p.bias, val, "metric=%s setPoint=%d error=%s integral=%s derivative=%s",
+		formatFloat(currentMetric), p.setPoint, formatFloat(errVal), formatFloat(integral), formatFloat(derivative))
+
+	if val < p.minimum {
+		e.AddStep("minimum", val, p.minimum, "minimum=%d", p.minimum)
+		val = p.minimum
+	}
+	if p.maximum != nil && val > *p.maximum {
+		e.AddStep("maximum", val, *p.maximum, "maximum=%d", *p.maximum)
+		val = *p.maximum
+	}
+
+	e.Value = val
+	return e, nil
+}
+
+// output returns the output of the controller before it is rounded and bounded
+func (p *Policy) output(errVal, integral, derivative float64) float64 {
+	return float64(p.bias) + p.kp*errVal + p.ki*integral + p.kd*derivative
+}
+
+// lastState returns the integral, the error of the last estimation and the
+// seconds elapsed since then. The state starts over when it is missing,
+// broken or older than maxGap.
+func (p *Policy) lastState(now time.Time) (float64, float64, float64) {
+	if p.state == nil || now.Sub(p.state.LastTime.Time) > p.maxGap {
+		return 0, 0, 0
+	}
+	integral, err := strconv.ParseFloat(p.state.Integral, 64)
+	if err != nil {
+		return 0, 0, 0
+	}
+	lastErr, err := strconv.ParseFloat(p.state.LastError, 64)
+	if err != nil {
+		return 0, 0, 0
+	}
+	dt := now.Sub(p.state.LastTime.Time).Seconds()
+	if dt < 0 {
+		dt = 0
+	}
+	return integral, lastErr, dt
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func init() {
+	rebalancev1.RegisterPolicy(&Policy{}, &rebalancev1.RebalancePolicy{
+		PID: &rebalancev1.PIDPolicy{},
+	})
+}
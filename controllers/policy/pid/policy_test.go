@@ -0,0 +1,246 @@
+package pid
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeMetrics struct {
+	value float64
+}
+
+func (m *fakeMetrics) Evaluate(ctx context.Context, expression string) (bool, error) {
+	return true, nil
+}
+
+func (m *fakeMetrics) Fetch(ctx context.Context) (float64, error) {
+	return m.value, nil
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestEstimate(t *testing.T) {
+	now := time.Date(2022, 12, 20, 12, 0, 0, 0, time.UTC)
+	lastState := &rebalancev1.PIDState{
+		Integral:  "1000",
+		LastError: "100",
+		LastTime:  metav1.NewTime(now.Add(-10 * time.Second)),
+	}
+
+	tests := []struct {
+		name         string
+		spec         rebalancev1.PIDPolicy
+		state        *rebalancev1.PIDState
+		metric       float64
+		want         int64
+		wantIntegral string
+	}{
+		{
+			"proportional only",
+			rebalancev1.PIDPolicy{SetPoint: 1000, Kp: "0.05", Bias: 10},
+			nil,
+			1200,
+			20,
+			"0",
+		},
+		{
+			"integral and derivative from the last state",
+			rebalancev1.PIDPolicy{SetPoint: 1000, Kp: "0.05", Ki: "0.001", Kd: "0.1"},
+			lastState,
+			1200,
+			// 0.05*200 + 0.001*(1000+200*10) + 0.1*(200-100)/10
+			14,
+			"3000",
+		},
+		{
+			"integral limited",
+			rebalancev1.PIDPolicy{SetPoint: 1000, Kp: "0", Ki: "0.01", IntegralLimit: "1500"},
+			lastState,
+			1200,
+			15,
+			"1500",
+		},
+		{
+			"bounded by minimum",
+			rebalancev1.PIDPolicy{SetPoint: 1000, Kp: "0.05", Minimum: 1},
+			nil,
+			500,
+			1,
+			"0",
+		},
+		{
+			"bounded by maximum",
+			rebalancev1.PIDPolicy{SetPoint: 1000, Kp: "1", Maximum: int64Ptr(100)},
+			nil,
+			5000,
+			100,
+			"0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := tt.spec
+			rb := &rebalancev1.Rebalance{
+				Spec: rebalancev1.RebalanceSpec{
+					Policy: rebalancev1.RebalancePolicy{PID: &spec},
+				},
+				Status: rebalancev1.RebalanceStatus{PIDState: tt.state},
+			}
+			var metrics rebalancev1.MetricsClient = &fakeMetrics{tt.metric}
+			estimator, err := (&Policy{}).New(context.Background(), rb, nil, nil, &metrics)
+			if err != nil {
+				t.Fatal(err)
+			}
+			estimator.(*Policy).now = func() time.Time { return now }
+
+			got, err := estimator.Estimate(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Value != tt.want {
+				t.Errorf("Estimate() = %v, want %v (%s)", got.Value, tt.want, got)
+			}
+			if got.PIDState.Integral != tt.wantIntegral {
+				t.Errorf("Estimate() integral = %v, want %v", got.PIDState.Integral, tt.wantIntegral)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    rebalancev1.PIDPolicy
+		wantErr bool
+	}{
+		{"valid", rebalancev1.PIDPolicy{Kp: "0.1", Ki: "0.01", Kd: "-0.5", IntegralLimit: "100"}, false},
+		{"missing kp", rebalancev1.PIDPolicy{Ki: "0.01"}, true},
+		{"malformed gain", rebalancev1.PIDPolicy{Kp: "0.1", Kd: "fast"}, true},
+		{"negative integral limit", rebalancev1.PIDPolicy{Kp: "0.1", IntegralLimit: "-1"}, true},
+		{"maximum below minimum", rebalancev1.PIDPolicy{Kp: "0.1", Minimum: 10, Maximum: int64Ptr(5)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := tt.spec
+			rb := rebalancev1.Rebalance{
+				Spec: rebalancev1.RebalanceSpec{
+					Policy: rebalancev1.RebalancePolicy{PID: &spec},
+				},
+			}
+			if err := (&Policy{}).Validate(rb); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEstimateStaleState(t *testing.T) {
+	now := time.Date(2022, 12, 20, 12, 0, 0, 0, time.UTC)
+	spec := rebalancev1.PIDPolicy{SetPoint: 1000, Kp: "0", Ki: "0.01"}
+
+	tests := []struct {
+		name         string
+		interval     string
+		mode         string
+		lastTime     time.Time
+		wantIntegral string
+	}{
+		{"recent state", "", rebalancev1.ModePolicy, now.Add(-time.Minute), "13000"},
+		{"long gap", "", rebalancev1.ModePolicy, now.Add(-time.Hour), "0"},
+		{"gap within the intervals", "30m", rebalancev1.ModePolicy, now.Add(-time.Hour), "721000"},
+		{"resumed from suspended", "", rebalancev1.ModeSuspended, now.Add(-time.Minute), "0"},
+		{"resumed from override", "", rebalancev1.ModeOverride, now.Add(-time.Minute), "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := spec
+			rb := &rebalancev1.Rebalance{
+				Spec: rebalancev1.RebalanceSpec{
+					Interval: tt.interval,
+					Policy:   rebalancev1.RebalancePolicy{PID: &spec},
+				},
+				Status: rebalancev1.RebalanceStatus{
+					Mode: tt.mode,
+					PIDState: &rebalancev1.PIDState{
+						Integral:  "1000",
+						LastError: "200",
+						LastTime:  metav1.NewTime(tt.lastTime),
+					},
+				},
+			}
+			var metrics rebalancev1.MetricsClient = &fakeMetrics{1200}
+			estimator, err := (&Policy{}).New(context.Background(), rb, nil, nil, &metrics)
+			if err != nil {
+				t.Fatal(err)
+			}
+			estimator.(*Policy).now = func() time.Time { return now }
+
+			got, err := estimator.Estimate(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.PIDState.Integral != tt.wantIntegral {
+				t.Errorf("Estimate() integral = %v, want %v", got.PIDState.Integral, tt.wantIntegral)
+			}
+		})
+	}
+}
+
+func TestEstimateAntiWindup(t *testing.T) {
+	now := time.Date(2022, 12, 20, 12, 0, 0, 0, time.UTC)
+	spec := rebalancev1.PIDPolicy{SetPoint: 1000, Kp: "0.1", Ki: "0.01", Maximum: int64Ptr(10)}
+	rb := &rebalancev1.Rebalance{
+		Spec: rebalancev1.RebalanceSpec{
+			Policy: rebalancev1.RebalancePolicy{PID: &spec},
+		},
+	}
+	metrics := &fakeMetrics{1200}
+	var client rebalancev1.MetricsClient = metrics
+
+	// the error stays above the set point while the output is capped to the maximum
+	for i := 0; i < 10; i++ {
+		estimator, err := (&Policy{}).New(context.Background(), rb, nil, nil, &client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		estimator.(*Policy).now = func() time.Time { return now }
+		got, err := estimator.Estimate(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Value != 10 {
+			t.Fatalf("Estimate() = %v, want 10", got.Value)
+		}
+		rb.Status.PIDState = got.PIDState
+		rb.Status.Mode = rebalancev1.ModePolicy
+		now = now.Add(time.Minute)
+	}
+	if integral := rb.Status.PIDState.Integral; integral != "0" {
+		t.Errorf("integral = %v, want 0 while the output is saturated", integral)
+	}
+
+	// the output leaves the maximum as soon as the error turns
+	metrics.value = 900
+	estimator, err := (&Policy{}).New(context.Background(), rb, nil, nil, &client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	estimator.(*Policy).now = func() time.Time { return now }
+	got, err := estimator.Estimate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 0.1*-100 bounded by the minimum, without an integral wound up at the maximum
+	if got.Value != 0 {
+		t.Errorf("Estimate() = %v, want 0", got.Value)
+	}
+}
@@ -1,5 +1,10 @@
 package register
 
 import (
+	_ "git.pepabo.com/akichan/rebalancer/controllers/policy/composite"
+	_ "git.pepabo.com/akichan/rebalancer/controllers/policy/expression"
+	_ "git.pepabo.com/akichan/rebalancer/controllers/policy/pid"
+	_ "git.pepabo.com/akichan/rebalancer/controllers/policy/schedule"
+	_ "git.pepabo.com/akichan/rebalancer/controllers/policy/stepscaling"
 	_ "git.pepabo.com/akichan/rebalancer/controllers/policy/targettracking"
 )
@@ -0,0 +1,78 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"git.pepabo.com/akichan/rebalancer/controllers/policy/calendar"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type Policy struct {
+	defaultValue int64
+	scheduled    []rebalancev1.Scheduled
+	now          func() time.Time
+}
+
+func (p *Policy) New(ctx context.Context, rebalance *rebalancev1.Rebalance, c client.Client,
+	target *rebalancev1.TargetClient, metrics *rebalancev1.MetricsClient) (rebalancev1.Estimator, error) {
+
+	return &Policy{
+		defaultValue: rebalance.Spec.Policy.Schedule.DefaultValue,
+		scheduled:    rebalance.Spec.Policy.Schedule.Scheduled,
+		now:          time.Now,
+	}, nil
+}
+
+func (p *Policy) Validate(rebalance rebalancev1.Rebalance) error {
+	spec := rebalance.Spec.Policy.Schedule
+	var errs []error
+
+	if spec.DefaultValue < 0 {
+		errs = append(errs, fmt.Errorf("defaultValue must not be negative: %d", spec.DefaultValue))
+	}
+	if len(spec.Scheduled) == 0 {
+		errs = append(errs, fmt.Errorf("scheduled must not be empty"))
+	}
+	for i, s := range spec.Scheduled {
+		if _, err := calendar.Parse(s); err != nil {
+			errs = append(errs, fmt.Errorf("scheduled[%d]: %w", i, err))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// NeedsMetrics implements rebalancev1.MetricsRequirement
+func (p *Policy) NeedsMetrics(rebalance rebalancev1.Rebalance) bool {
+	return false
+}
+
+func (p *Policy) Estimate(ctx context.Context) (rebalancev1.Estimation, error) {
+	var e rebalancev1.Estimation
+
+	active, err := calendar.Active(p.scheduled, p.now())
+	if err != nil {
+		return e, fmt.Errorf("failed to check scheduled value: %w", err)
+	}
+
+	if len(active) == 0 {
+		e.AddStep("schedule", 0, p.defaultValue, "window=default")
+		e.Value = p.defaultValue
+		return e, nil
+	}
+
+	w := active[0]
+	e.AddStep("schedule", 0, w.Value, "window=%s", w)
+	e.Value = w.Value
+	return e, nil
+}
+
+func init() {
+	rebalancev1.RegisterPolicy(&Policy{}, &rebalancev1.RebalancePolicy{
+		Schedule: &rebalancev1.SchedulePolicy{},
+	})
+}
@@ -0,0 +1,57 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+func TestEstimate(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := rebalancev1.SchedulePolicy{
+		DefaultValue: 100,
+		Scheduled: []rebalancev1.Scheduled{
+			{StartTime: "12:00", EndTime: "13:00", TimeZone: "Asia/Tokyo", Value: 50},
+			{StartTime: "09:00", EndTime: "18:00", DaysOfWeek: []rebalancev1.Weekday{"Mon", "Tue", "Wed", "Thu", "Fri"}, TimeZone: "Asia/Tokyo", Value: 10},
+		},
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want int64
+	}{
+		{"business hours", time.Date(2022, 12, 20, 10, 0, 0, 0, tokyo), 10},
+		{"first window wins", time.Date(2022, 12, 20, 12, 30, 0, 0, tokyo), 50},
+		{"outside of the windows", time.Date(2022, 12, 20, 20, 0, 0, 0, tokyo), 100},
+		{"weekend", time.Date(2022, 12, 24, 10, 0, 0, 0, tokyo), 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rb := &rebalancev1.Rebalance{
+				Spec: rebalancev1.RebalanceSpec{
+					Policy: rebalancev1.RebalancePolicy{Schedule: &spec},
+				},
+			}
+			estimator, err := (&Policy{}).New(context.Background(), rb, nil, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			estimator.(*Policy).now = func() time.Time { return tt.now }
+
+			got, err := estimator.Estimate(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Value != tt.want {
+				t.Errorf("Estimate() = %v, want %v (%s)", got.Value, tt.want, got)
+			}
+		})
+	}
+}
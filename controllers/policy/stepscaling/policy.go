@@ -0,0 +1,164 @@
+package stepscaling
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type Policy struct {
+	target    *rebalancev1.TargetClient
+	metrics   *rebalancev1.MetricsClient
+	threshold int64
+	steps     []rebalancev1.ScalingStep
+}
+
+func (p *Policy) New(ctx context.Context, rebalance *rebalancev1.Rebalance, c client.Client,
+	target *rebalancev1.TargetClient, metrics *rebalancev1.MetricsClient) (rebalancev1.Estimator, error) {
+
+	metrics, err := rebalancev1.MetricsClientFor(ctx, *rebalance, c, rebalance.Spec.Policy.StepScaling.Metric, metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Policy{
+		target:    target,
+		metrics:   metrics,
+		threshold: rebalance.Spec.Policy.StepScaling.Threshold,
+		steps:     rebalance.Spec.Policy.StepScaling.Steps,
+	}, nil
+}
+
+func (p *Policy) Validate(rebalance rebalancev1.Rebalance) error {
+	spec := rebalance.Spec.Policy.StepScaling
+	var errs []error
+
+	if spec.Metric != "" {
+		if _, err := rebalance.WithNamedMetrics(spec.Metric); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(spec.Steps) == 0 {
+		errs = append(errs, fmt.Errorf("steps must not be empty"))
+	}
+	for i, s := range spec.Steps {
+		if (s.Weight == nil) == (s.Delta == nil) {
+			errs = append(errs, fmt.Errorf("steps[%d]: exactly one of weight and delta must be set", i))
+		}
+		if s.Weight != nil && *s.Weight < 0 {
+			errs = append(errs, fmt.Errorf("steps[%d]: weight must not be negative: %d", i, *s.Weight))
+		}
+		if s.LowerBound != nil && s.UpperBound != nil && *s.LowerBound >= *s.UpperBound {
+			errs = append(errs, fmt.Errorf("steps[%d]: upperBound %d must be greater than lowerBound %d", i, *s.UpperBound, *s.LowerBound))
+		}
+	}
+
+	// steps must not overlap
+	sorted := append([]rebalancev1.ScalingStep{}, spec.Steps...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return lower(sorted[i]) < lower(sorted[j])
+	})
+	for i := 1; i < len(sorted); i++ {
+		if upper(sorted[i-1]) > lower(sorted[i]) {
+			errs = append(errs, fmt.Errorf("steps %s and %s overlap", boundsString(sorted[i-1]), boundsString(sorted[i])))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// NeedsMetrics implements rebalancev1.MetricsRequirement
+func (p *Policy) NeedsMetrics(rebalance rebalancev1.Rebalance) bool {
+	return rebalance.Spec.Policy.StepScaling.Metric == ""
+}
+
+func (p *Policy) Estimate(ctx context.Context) (rebalancev1.Estimation, error) {
+	var e rebalancev1.Estimation
+
+	// get current metrics
+	currentMetric, err := (*p.metrics).Fetch(ctx)
+	if err != nil {
+		return e, fmt.Errorf("failed get current metric: %w", err)
+	}
+	e.Metric = &currentMetric
+
+	current, err := (*p.target).GetWeight(ctx)
+	if err != nil {
+		return e, fmt.Errorf("failed to get current weight: %w", err)
+	}
+
+	metric := strconv.FormatFloat(currentMetric, 'g', -1, 64)
+	step, ok := findStep(p.steps, currentMetric-float64(p.threshold))
+	if !ok {
+		e.AddStep("stepScaling", current, current, "metric=%s threshold=%d step=none", metric, p.threshold)
+		e.Value = current
+		return e, nil
+	}
+
+	val := current
+	if step.Weight != nil {
+		val = *step.Weight
+		e.AddStep("stepScaling", current, val, "metric=%s threshold=%d step=%s weight=%d",
+			metric, p.threshold, boundsString(step), *step.Weight)
+	} else {
+		val = current + *step.Delta
+		if val < 0 {
+			val = 0
+		}
+		e.AddStep("stepScaling", current, val, "metric=%s threshold=%d step=%s delta=%d",
+			metric, p.threshold, boundsString(step), *step.Delta)
+	}
+
+	e.Value = val
+	return e, nil
+}
+
+// findStep returns the step whose bounds contain the difference between the
+// metric and the threshold
+func findStep(steps []rebalancev1.ScalingStep, diff float64) (rebalancev1.ScalingStep, bool) {
+	for _, s := range steps {
+		if diff >= lower(s) && diff < upper(s) {
+			return s, true
+		}
+	}
+	return rebalancev1.ScalingStep{}, false
+}
+
+func lower(s rebalancev1.ScalingStep) float64 {
+	if s.LowerBound == nil {
+		return math.Inf(-1)
+	}
+	return float64(*s.LowerBound)
+}
+
+func upper(s rebalancev1.ScalingStep) float64 {
+	if s.UpperBound == nil {
+		return math.Inf(1)
+	}
+	return float64(*s.UpperBound)
+}
+
+// boundsString returns the bounds of the step like "[0,100)"
+func boundsString(s rebalancev1.ScalingStep) string {
+	l, u := "-inf", "+inf"
+	if s.LowerBound != nil {
+		l = strconv.FormatInt(*s.LowerBound, 10)
+	}
+	if s.UpperBound != nil {
+		u = strconv.FormatInt(*s.UpperBound, 10)
+	}
+	return fmt.Sprintf("[%s,%s)", l, u)
+}
+
+func init() {
+	rebalancev1.RegisterPolicy(&Policy{}, &rebalancev1.RebalancePolicy{
+		StepScaling: &rebalancev1.StepScalingPolicy{},
+	})
+}
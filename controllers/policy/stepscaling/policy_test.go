@@ -0,0 +1,153 @@
+package stepscaling
+
+import (
+	"context"
+	"testing"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+type fakeMetrics struct {
+	value float64
+}
+
+func (m *fakeMetrics) Evaluate(ctx context.Context, expression string) (bool, error) {
+	return true, nil
+}
+
+func (m *fakeMetrics) Fetch(ctx context.Context) (float64, error) {
+	return m.value, nil
+}
+
+type fakeTarget struct {
+	weight int64
+}
+
+func (t *fakeTarget) GetWeight(ctx context.Context) (int64, error) {
+	return t.weight, nil
+}
+
+func (t *fakeTarget) SetWeight(ctx context.Context, value int64) error {
+	t.weight = value
+	return nil
+}
+
+func (t *fakeTarget) WeightRange() (int64, int64) {
+	return 0, 255
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestEstimate(t *testing.T) {
+	spec := rebalancev1.StepScalingPolicy{
+		Threshold: 1000,
+		Steps: []rebalancev1.ScalingStep{
+			{UpperBound: int64Ptr(-500), Weight: int64Ptr(0)},
+			{LowerBound: int64Ptr(-500), UpperBound: int64Ptr(0), Delta: int64Ptr(-2)},
+			{LowerBound: int64Ptr(500), UpperBound: int64Ptr(1000), Delta: int64Ptr(5)},
+			{LowerBound: int64Ptr(1000), Weight: int64Ptr(50)},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		metric  float64
+		current int64
+		want    int64
+	}{
+		{"far below threshold", 100, 10, 0},
+		{"below threshold", 800, 10, 8},
+		{"delta does not go below zero", 800, 1, 0},
+		{"no step matches", 1200, 10, 10},
+		{"lower bound is inclusive", 1500, 10, 15},
+		{"upper bound is exclusive", 1999.9, 10, 15},
+		{"far above threshold", 2000, 10, 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rb := &rebalancev1.Rebalance{
+				Spec: rebalancev1.RebalanceSpec{
+					Policy: rebalancev1.RebalancePolicy{StepScaling: &spec},
+				},
+			}
+			var metrics rebalancev1.MetricsClient = &fakeMetrics{tt.metric}
+			var target rebalancev1.TargetClient = &fakeTarget{tt.current}
+			estimator, err := (&Policy{}).New(context.Background(), rb, nil, &target, &metrics)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := estimator.Estimate(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Value != tt.want {
+				t.Errorf("Estimate() = %v, want %v (%s)", got.Value, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []rebalancev1.ScalingStep
+		wantErr bool
+	}{
+		{
+			"valid",
+			[]rebalancev1.ScalingStep{
+				{UpperBound: int64Ptr(0), Delta: int64Ptr(-1)},
+				{LowerBound: int64Ptr(0), Delta: int64Ptr(1)},
+			},
+			false,
+		},
+		{"empty", nil, true},
+		{
+			"both weight and delta",
+			[]rebalancev1.ScalingStep{{Weight: int64Ptr(1), Delta: int64Ptr(1)}},
+			true,
+		},
+		{
+			"neither weight nor delta",
+			[]rebalancev1.ScalingStep{{LowerBound: int64Ptr(0)}},
+			true,
+		},
+		{
+			"negative weight",
+			[]rebalancev1.ScalingStep{{Weight: int64Ptr(-1)}},
+			true,
+		},
+		{
+			"upper bound below lower bound",
+			[]rebalancev1.ScalingStep{{LowerBound: int64Ptr(10), UpperBound: int64Ptr(0), Delta: int64Ptr(1)}},
+			true,
+		},
+		{
+			"overlapping steps",
+			[]rebalancev1.ScalingStep{
+				{UpperBound: int64Ptr(10), Delta: int64Ptr(-1)},
+				{LowerBound: int64Ptr(0), Delta: int64Ptr(1)},
+			},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rb := rebalancev1.Rebalance{
+				Spec: rebalancev1.RebalanceSpec{
+					Policy: rebalancev1.RebalancePolicy{
+						StepScaling: &rebalancev1.StepScalingPolicy{Threshold: 100, Steps: tt.steps},
+					},
+				},
+			}
+			if err := (&Policy{}).Validate(rb); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
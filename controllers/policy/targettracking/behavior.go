@@ -0,0 +1,115 @@
+package targettracking
+
+import (
+	"math"
+	"time"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func stabilizationWindow(rules *rebalancev1.ScalingRules) time.Duration {
+	if rules == nil {
+		return 0
+	}
+	return time.Duration(rules.StabilizationWindowSeconds) * time.Second
+}
+
+func cooldown(rules *rebalancev1.ScalingRules) time.Duration {
+	if rules == nil {
+		return 0
+	}
+	return time.Duration(rules.CooldownSeconds) * time.Second
+}
+
+// stabilize returns the weight following the recommendation value as far as
+// the recommendations within the stabilization windows agree with it.
+// Scaling out is limited to the lowest recommendation of the scale out window
+// and scaling in is limited to the highest recommendation of the scale in window.
+func stabilize(b *rebalancev1.ScalingBehavior, recs []rebalancev1.Recommendation, current, value int64, now time.Time) int64 {
+	up, down := value, value
+	outWindow, inWindow := stabilizationWindow(b.ScaleOut), stabilizationWindow(b.ScaleIn)
+	for _, r := range recs {
+		age := now.Sub(r.Time.Time)
+		if age < outWindow && r.Value < up {
+			up = r.Value
+		}
+		if age < inWindow && r.Value > down {
+			down = r.Value
+		}
+	}
+
+	next := current
+	if next < up {
+		next = up
+	}
+	if next > down {
+		next = down
+	}
+	return next
+}
+
+// coolingDown reports whether changing the weight from current to next is
+// blocked by the cooldown of the direction
+func coolingDown(b *rebalancev1.ScalingBehavior, lastScaleTime *metav1.Time, current, next int64, now time.Time) bool {
+	if lastScaleTime == nil || next == current {
+		return false
+	}
+	rules := b.ScaleIn
+	if next > current {
+		rules = b.ScaleOut
+	}
+	return now.Before(lastScaleTime.Add(cooldown(rules)))
+}
+
+// limitStep limits the change of the weight from current to next by the
+// maximum step of the direction
+func limitStep(b *rebalancev1.ScalingBehavior, current, next int64) int64 {
+	rules := b.ScaleIn
+	if next > current {
+		rules = b.ScaleOut
+	}
+	if rules == nil || (rules.MaxStep == 0 && rules.MaxStepPercent == 0) {
+		return next
+	}
+
+	step := rules.MaxStep
+	if rules.MaxStepPercent > 0 {
+		s := int64(math.Ceil(float64(current) * float64(rules.MaxStepPercent) / 100))
+		if s < 1 {
+			s = 1
+		}
+		if s > step {
+			step = s
+		}
+	}
+
+	switch {
+	case next > current+step:
+		return current + step
+	case next < current-step:
+		return current - step
+	default:
+		return next
+	}
+}
+
+// keepRecommendations appends the recommendation and drops the ones no
+// stabilization window looks at anymore
+func keepRecommendations(b *rebalancev1.ScalingBehavior, recs []rebalancev1.Recommendation, value int64, now time.Time) []rebalancev1.Recommendation {
+	window := stabilizationWindow(b.ScaleOut)
+	if w := stabilizationWindow(b.ScaleIn); w > window {
+		window = w
+	}
+	if window == 0 {
+		return nil
+	}
+
+	kept := make([]rebalancev1.Recommendation, 0, len(recs)+1)
+	for _, r := range recs {
+		if now.Sub(r.Time.Time) < window {
+			kept = append(kept, r)
+		}
+	}
+	return append(kept, rebalancev1.Recommendation{Time: metav1.NewTime(now), Value: value})
+}
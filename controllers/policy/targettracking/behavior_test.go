@@ -0,0 +1,118 @@
+package targettracking
+
+import (
+	"testing"
+	"time"
+
+	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStabilize(t *testing.T) {
+	now := time.Date(2022, 12, 20, 12, 0, 0, 0, time.UTC)
+	ago := func(s int) metav1.Time { return metav1.NewTime(now.Add(-time.Duration(s) * time.Second)) }
+	behavior := &rebalancev1.ScalingBehavior{
+		ScaleOut: &rebalancev1.ScalingRules{StabilizationWindowSeconds: 60},
+		ScaleIn:  &rebalancev1.ScalingRules{StabilizationWindowSeconds: 300},
+	}
+	recs := []rebalancev1.Recommendation{
+		{Time: ago(240), Value: 30},
+		{Time: ago(120), Value: 10},
+		{Time: ago(30), Value: 15},
+	}
+
+	tests := []struct {
+		name    string
+		current int64
+		value   int64
+		want    int64
+	}{
+		{"scale out limited to the lowest recommendation in the window", 10, 40, 15},
+		{"scale in limited to the highest recommendation in the window", 20, 5, 20},
+		{"scale in down to the highest recommendation in the window", 40, 5, 30},
+		{"within the recommendations", 12, 20, 15},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stabilize(behavior, recs, tt.current, tt.value, now); got != tt.want {
+				t.Errorf("stabilize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoolingDown(t *testing.T) {
+	now := time.Date(2022, 12, 20, 12, 0, 0, 0, time.UTC)
+	last := metav1.NewTime(now.Add(-2 * time.Minute))
+	behavior := &rebalancev1.ScalingBehavior{
+		ScaleOut: &rebalancev1.ScalingRules{CooldownSeconds: 60},
+		ScaleIn:  &rebalancev1.ScalingRules{CooldownSeconds: 300},
+	}
+
+	tests := []struct {
+		name string
+		last *metav1.Time
+		next int64
+		want bool
+	}{
+		{"scale out after cooldown", &last, 20, false},
+		{"scale in during cooldown", &last, 5, true},
+		{"never scaled", nil, 5, false},
+		{"no change", &last, 10, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := coolingDown(behavior, tt.last, 10, tt.next, now); got != tt.want {
+				t.Errorf("coolingDown() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeepRecommendations(t *testing.T) {
+	now := time.Date(2022, 12, 20, 12, 0, 0, 0, time.UTC)
+	behavior := &rebalancev1.ScalingBehavior{
+		ScaleIn: &rebalancev1.ScalingRules{StabilizationWindowSeconds: 60},
+	}
+	recs := []rebalancev1.Recommendation{
+		{Time: metav1.NewTime(now.Add(-90 * time.Second)), Value: 1},
+		{Time: metav1.NewTime(now.Add(-30 * time.Second)), Value: 2},
+	}
+
+	got := keepRecommendations(behavior, recs, 3, now)
+	if len(got) != 2 || got[0].Value != 2 || got[1].Value != 3 {
+		t.Errorf("keepRecommendations() = %v", got)
+	}
+	if got := keepRecommendations(&rebalancev1.ScalingBehavior{}, recs, 3, now); got != nil {
+		t.Errorf("keepRecommendations() without windows = %v, want nil", got)
+	}
+}
+
+func TestLimitStep(t *testing.T) {
+	behavior := &rebalancev1.ScalingBehavior{
+		ScaleOut: &rebalancev1.ScalingRules{MaxStep: 5},
+		ScaleIn:  &rebalancev1.ScalingRules{MaxStep: 2, MaxStepPercent: 50},
+	}
+
+	tests := []struct {
+		name    string
+		current int64
+		next    int64
+		want    int64
+	}{
+		{"scale out limited", 0, 40, 5},
+		{"scale out within the limit", 10, 13, 13},
+		{"scale in limited by percent", 20, 0, 10},
+		{"scale in limited by step", 3, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limitStep(behavior, tt.current, tt.next); got != tt.want {
+				t.Errorf("limitStep() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
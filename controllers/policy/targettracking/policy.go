@@ -9,7 +9,11 @@ import (
 	"time"
 
 	rebalancev1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"git.pepabo.com/akichan/rebalancer/controllers/policy/calendar"
 	"github.com/thoas/go-funk"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type Policy struct {
@@ -20,10 +24,19 @@ type Policy struct {
 	disableScaleIn      bool
 	scheduled           []rebalancev1.Scheduled
 	minimum             int64
+	maximum             *int64
+	behavior            *rebalancev1.ScalingBehavior
+	recommendations     []rebalancev1.Recommendation
+	lastScaleTime       *metav1.Time
 }
 
-func (p *Policy) New(rebalance *rebalancev1.Rebalance, target *rebalancev1.TargetClient,
-	metrics *rebalancev1.MetricsClient) (rebalancev1.Estimator, error) {
+func (p *Policy) New(ctx context.Context, rebalance *rebalancev1.Rebalance, c client.Client,
+	target *rebalancev1.TargetClient, metrics *rebalancev1.MetricsClient) (rebalancev1.Estimator, error) {
+
+	metrics, err := rebalancev1.MetricsClientFor(ctx, *rebalance, c, rebalance.Spec.Policy.TargetTracking.Metric, metrics)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Policy{
 		target:              target,
@@ -33,33 +46,134 @@ func (p *Policy) New(rebalance *rebalancev1.Rebalance, target *rebalancev1.Targe
 		disableScaleIn:      rebalance.Spec.Policy.TargetTracking.DisableScaleIn,
 		scheduled:           rebalance.Spec.Policy.TargetTracking.Scheduled,
 		minimum:             rebalance.Spec.Policy.TargetTracking.Minimum,
+		maximum:             rebalance.Spec.Policy.TargetTracking.Maximum,
+		behavior:            rebalance.Spec.Policy.TargetTracking.Behavior,
+		recommendations:     rebalance.Status.Recommendations,
+		lastScaleTime:       rebalance.Status.LastScaleTime,
 	}, nil
 }
 
-func (p *Policy) Estimate(ctx context.Context) (int64, error) {
+func (p *Policy) Validate(rebalance rebalancev1.Rebalance) error {
+	spec := rebalance.Spec.Policy.TargetTracking
+	var errs []error
+
+	if spec.Metric != "" {
+		if _, err := rebalance.WithNamedMetrics(spec.Metric); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if spec.TargetValue <= 0 {
+		errs = append(errs, fmt.Errorf("targetValue must be positive: %d", spec.TargetValue))
+	}
+	if spec.BaseValue < 0 {
+		errs = append(errs, fmt.Errorf("baseValue must not be negative: %d", spec.BaseValue))
+	}
+	if spec.Minimum < 0 {
+		errs = append(errs, fmt.Errorf("minimum must not be negative: %d", spec.Minimum))
+	}
+	if spec.Maximum != nil && *spec.Maximum < spec.Minimum {
+		errs = append(errs, fmt.Errorf("maximum %d must not be less than minimum %d", *spec.Maximum, spec.Minimum))
+	}
+	for i, s := range spec.Scheduled {
+		if _, err := calendar.Parse(s); err != nil {
+			errs = append(errs, fmt.Errorf("scheduled[%d]: %w", i, err))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// NeedsMetrics implements rebalancev1.MetricsRequirement
+func (p *Policy) NeedsMetrics(rebalance rebalancev1.Rebalance) bool {
+	return rebalance.Spec.Policy.TargetTracking.Metric == ""
+}
+
+func (p *Policy) Estimate(ctx context.Context) (rebalancev1.Estimation, error) {
+	var e rebalancev1.Estimation
+
 	// get current metrics
 	currentMetric, err := (*p.metrics).Fetch(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed get current metric: %w", err)
+		return e, fmt.Errorf("failed get current metric: %w", err)
 	}
+	e.Metric = &currentMetric
 
 	// process desired value
 	val := processBestContrast(float64(p.baseValue), float64(p.trackingTargetValue), currentMetric)
+	e.AddStep("targetTracking", 0, val, "metric=%s targetValue=%d baseValue=%d",
+		strconv.FormatFloat(currentMetric, 'g', -1, 64), p.trackingTargetValue, p.baseValue)
+
+	var current int64
+	if p.behavior != nil {
+		current, err = (*p.target).GetWeight(ctx)
+		if err != nil {
+			return e, fmt.Errorf("failed to get current weight: %w", err)
+		}
+		val = p.applyBehavior(&e, val, current, time.Now())
+	}
+
 	minimum := p.minimum
 	if val < minimum {
+		e.AddStep("minimum", val, minimum, "minimum=%d", minimum)
 		val = minimum
 	}
 
 	// check scheduled values
 	if len(p.scheduled) > 0 {
 		nowTime := time.Now()
-		val, err = checkScheduledValue(p.scheduled, val, nowTime)
+		scheduled, err := checkScheduledValue(p.scheduled, val, nowTime)
 		if err != nil {
-			return 0, fmt.Errorf("failed to check scheduled value")
+			return e, fmt.Errorf("failed to check scheduled value: %w", err)
+		}
+		if scheduled != val {
+			// the windows were parsed successfully above
+			active, _ := calendar.Active(p.scheduled, nowTime)
+			windows := make([]string, 0, len(active))
+			for _, w := range active {
+				windows = append(windows, fmt.Sprintf("%s=%d", w, w.Value))
+			}
+			e.AddStep("scheduled", val, scheduled, "active=%s", strings.Join(windows, ","))
+			val = scheduled
 		}
 	}
 
-	return val, nil
+	if p.maximum != nil && val > *p.maximum {
+		e.AddStep("maximum", val, *p.maximum, "maximum=%d", *p.maximum)
+		val = *p.maximum
+	}
+
+	if p.behavior != nil {
+		if limited := limitStep(p.behavior, current, val); limited != val {
+			e.AddStep("stepLimit", val, limited, "current=%d", current)
+			ideal := val
+			e.Ideal = &ideal
+			val = limited
+		}
+	}
+
+	e.Value = val
+	return e, nil
+}
+
+// applyBehavior smooths the recommendation with the stabilization windows and
+// the cooldowns of the scaling behavior
+func (p *Policy) applyBehavior(e *rebalancev1.Estimation, val, current int64, now time.Time) int64 {
+	e.Recommendations = keepRecommendations(p.behavior, p.recommendations, val, now)
+
+	next := stabilize(p.behavior, p.recommendations, current, val, now)
+	if next != val {
+		e.AddStep("stabilization", val, next, "current=%d scaleOutWindow=%s scaleInWindow=%s",
+			current, stabilizationWindow(p.behavior.ScaleOut), stabilizationWindow(p.behavior.ScaleIn))
+		val = next
+	}
+
+	if coolingDown(p.behavior, p.lastScaleTime, current, val, now) {
+		e.AddStep("cooldown", val, current, "current=%d lastScaleTime=%s",
+			current, p.lastScaleTime.UTC().Format(time.RFC3339))
+		val = current
+	}
+	return val
 }
 
 func processBestContrast(base float64, trackingTargetVal float64, current float64) int64 {
@@ -71,37 +185,19 @@ func processBestContrast(base float64, trackingTargetVal float64, current float6
 }
 
 func checkScheduledValue(scheduled []rebalancev1.Scheduled, v int64, nowTime time.Time) (int64, error) {
+	active, err := calendar.Active(scheduled, nowTime)
+	if err != nil {
+		return 0, err
+	}
+
 	var values []int
 	values = append(values, int(v))
-	for _, s := range scheduled {
-		startTime, err := parseTime(s.StartTime, nowTime)
-		if err != nil {
-			return 0, err
-		}
-		endTime, _ := parseTime(s.EndTime, nowTime)
-		if err != err {
-			return 0, err
-		}
-		if (nowTime.Equal(startTime) || nowTime.After(startTime)) && nowTime.Before(endTime) {
-			values = append(values, int(s.Value))
-		}
+	for _, w := range active {
+		values = append(values, int(w.Value))
 	}
 	return int64(funk.MaxInt(values)), nil
 }
 
-func parseTime(t string, n time.Time) (time.Time, error) {
-	ts := strings.Split(t, ":")
-	hour, err := strconv.Atoi(ts[0])
-	if err != nil {
-		return time.Time{}, err
-	}
-	min, err := strconv.Atoi(ts[1])
-	if err != nil {
-		return time.Time{}, err
-	}
-	return time.Date(n.Year(), n.Month(), n.Day(), hour, min, 0, 0, time.Local), nil
-}
-
 func init() {
 	rebalancev1.RegisterPolicy(&Policy{}, &rebalancev1.RebalancePolicy{
 		TargetTracking: &rebalancev1.TargetTrackingPolicy{},
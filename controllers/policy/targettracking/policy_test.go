@@ -1,6 +1,8 @@
 package targettracking
 
 import (
+	"context"
+	"reflect"
 	"testing"
 	"time"
 
@@ -107,3 +109,169 @@ func TestCheckScheduledValue(t *testing.T) {
 	}
 
 }
+
+func TestValidate(t *testing.T) {
+	valid := rebalancev1.TargetTrackingPolicy{
+		TargetValue: 100,
+		BaseValue:   10,
+		Scheduled: []rebalancev1.Scheduled{
+			{StartTime: "09:00", EndTime: "18:30", Value: 2},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(p *rebalancev1.TargetTrackingPolicy)
+		wantErr bool
+	}{
+		{"valid", func(p *rebalancev1.TargetTrackingPolicy) {}, false},
+		{"zero target value", func(p *rebalancev1.TargetTrackingPolicy) { p.TargetValue = 0 }, true},
+		{"negative base value", func(p *rebalancev1.TargetTrackingPolicy) { p.BaseValue = -1 }, true},
+		{"negative minimum", func(p *rebalancev1.TargetTrackingPolicy) { p.Minimum = -1 }, true},
+		{"maximum below minimum", func(p *rebalancev1.TargetTrackingPolicy) { p.Minimum, p.Maximum = 10, int64Ptr(5) }, true},
+		{"malformed start time", func(p *rebalancev1.TargetTrackingPolicy) { p.Scheduled[0].StartTime = "9am" }, true},
+		{"malformed end time", func(p *rebalancev1.TargetTrackingPolicy) { p.Scheduled[0].EndTime = "18" }, true},
+		{"overnight window", func(p *rebalancev1.TargetTrackingPolicy) { p.Scheduled[0].EndTime = "08:00" }, false},
+		{"end equals start", func(p *rebalancev1.TargetTrackingPolicy) { p.Scheduled[0].EndTime = "09:00" }, true},
+		{"unknown named metrics", func(p *rebalancev1.TargetTrackingPolicy) { p.Metric = "latency" }, true},
+		{"unknown time zone", func(p *rebalancev1.TargetTrackingPolicy) { p.Scheduled[0].TimeZone = "Mars/Olympus" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := valid
+			spec.Scheduled = append([]rebalancev1.Scheduled{}, valid.Scheduled...)
+			tt.modify(&spec)
+			rb := rebalancev1.Rebalance{
+				Spec: rebalancev1.RebalanceSpec{
+					Policy: rebalancev1.RebalancePolicy{TargetTracking: &spec},
+				},
+			}
+			if err := (&Policy{}).Validate(rb); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type fakeMetrics struct {
+	value float64
+}
+
+func (m *fakeMetrics) Evaluate(ctx context.Context, expression string) (bool, error) {
+	return true, nil
+}
+
+func (m *fakeMetrics) Fetch(ctx context.Context) (float64, error) {
+	return m.value, nil
+}
+
+type fakeTarget struct {
+	weight int64
+}
+
+func (t *fakeTarget) GetWeight(ctx context.Context) (int64, error) {
+	return t.weight, nil
+}
+
+func (t *fakeTarget) SetWeight(ctx context.Context, value int64) error {
+	t.weight = value
+	return nil
+}
+
+func (t *fakeTarget) WeightRange() (int64, int64) {
+	return 0, 255
+}
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      rebalancev1.TargetTrackingPolicy
+		metric    float64
+		want      int64
+		wantIdeal *int64
+		wantSteps []string
+	}{
+		{
+			"target tracking only",
+			rebalancev1.TargetTrackingPolicy{TargetValue: 1000, BaseValue: 8},
+			3000,
+			16,
+			nil,
+			[]string{"targetTracking"},
+		},
+		{
+			"raised to minimum",
+			rebalancev1.TargetTrackingPolicy{TargetValue: 1000, BaseValue: 8, Minimum: 20},
+			3000,
+			20,
+			nil,
+			[]string{"targetTracking", "minimum"},
+		},
+		{
+			"capped to maximum",
+			rebalancev1.TargetTrackingPolicy{TargetValue: 1000, BaseValue: 8, Maximum: int64Ptr(10)},
+			3000,
+			10,
+			nil,
+			[]string{"targetTracking", "maximum"},
+		},
+		{
+			"minimum does not contribute",
+			rebalancev1.TargetTrackingPolicy{TargetValue: 1000, BaseValue: 8, Minimum: 2},
+			3000,
+			16,
+			nil,
+			[]string{"targetTracking"},
+		},
+		{
+			"limited by the max step",
+			rebalancev1.TargetTrackingPolicy{TargetValue: 1000, BaseValue: 20, Behavior: &rebalancev1.ScalingBehavior{
+				ScaleOut: &rebalancev1.ScalingRules{MaxStep: 5},
+			}},
+			3000,
+			5,
+			int64Ptr(40),
+			[]string{"targetTracking", "stepLimit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := tt.spec
+			rb := &rebalancev1.Rebalance{
+				Spec: rebalancev1.RebalanceSpec{
+					Policy: rebalancev1.RebalancePolicy{TargetTracking: &spec},
+				},
+			}
+			var metrics rebalancev1.MetricsClient = &fakeMetrics{tt.metric}
+			var target rebalancev1.TargetClient = &fakeTarget{}
+			estimator, err := (&Policy{}).New(context.Background(), rb, nil, &target, &metrics)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := estimator.Estimate(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Value != tt.want {
+				t.Errorf("Estimate() = %v, want %v", got.Value, tt.want)
+			}
+			if !reflect.DeepEqual(got.Ideal, tt.wantIdeal) {
+				t.Errorf("Estimate() ideal = %v, want %v", got.Ideal, tt.wantIdeal)
+			}
+			var steps []string
+			for _, s := range got.Steps {
+				steps = append(steps, s.Name)
+			}
+			if !reflect.DeepEqual(steps, tt.wantSteps) {
+				t.Errorf("Estimate() steps = %v, want %v", steps, tt.wantSteps)
+			}
+		})
+	}
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
@@ -18,11 +18,19 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"time"
 
-	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,13 +46,16 @@ import (
 // RebalanceReconciler reconciles a Rebalance object
 type RebalanceReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=rebalancer.ch1aki.github.io,resources=rebalances,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=rebalancer.ch1aki.github.io,resources=rebalances/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=rebalancer.ch1aki.github.io,resources=rebalances/finalizers,verbs=update
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes;grpcroutes,verbs=get;list;watch;update;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -60,8 +71,8 @@ func (r *RebalanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	var rb rebalancerv1.Rebalance
 	err := r.Get(ctx, req.NamespacedName, &rb)
-	if errors.IsNotFound(err) {
-		r.removeMetrics(rb)
+	if apierrors.IsNotFound(err) {
+		r.removeMetrics(req.NamespacedName)
 		return ctrl.Result{}, nil
 	}
 	if err != nil {
@@ -73,120 +84,388 @@ func (r *RebalanceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
+	if rb.Spec.Interval == "" {
+		rb.Spec.Interval = rebalancerv1.DefaultInterval
+	}
 	interval, err := time.ParseDuration(rb.Spec.Interval)
 	if err != nil {
 		logger.Error(err, "unable to parse interval string", "interval", rb.Spec.Interval)
+		err = &rebalanceError{
+			condition: rebalancerv1.ConditionReady,
+			reason:    rebalancerv1.ReasonInvalidSpec,
+			err:       fmt.Errorf("unable to parse interval: %w", err),
+		}
+		r.recordEvents(&rb, rebalanceResult{}, err)
+		if err := r.updateStatus(ctx, rb, rebalanceResult{}, err); err != nil {
+			logger.Error(err, "unable to update status")
+		}
 		return ctrl.Result{}, err
 	}
 
-	desired, actual, err := r.rebalance(ctx, rb, r.Client)
+	now := time.Now()
+	mode := activeMode(rb, now)
+	result, err := r.rebalance(ctx, &rb, r.Client, mode)
 	if err != nil {
 		logger.Error(err, "rebalance operation failed", "interval", rb.Spec.Interval)
-		return ctrl.Result{}, err
 	}
+	r.recordEvents(&rb, result, err)
 
-	err = r.updateStatus(ctx, rb, desired, actual)
-	if err != nil {
+	if err := r.updateStatus(ctx, rb, result, err); err != nil {
 		logger.Error(err, "rebalance operation failed", "update status", rb.Spec)
 	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
 
 	return ctrl.Result{
-		RequeueAfter: interval,
+		RequeueAfter: requeueAfter(rb, mode, interval, now),
 	}, nil
 }
 
-func (r *RebalanceReconciler) updateStatus(ctx context.Context, rb rebalancerv1.Rebalance, desired int64, actual int64) error {
-	var status rebalancerv1.RebalanceStatus
+// rebalanceResult is the outcome of a rebalance operation
+type rebalanceResult struct {
+	// mode is the mode the operation ran in
+	mode string
+
+	desired int64
+	actual  int64
+	members []rebalancerv1.MemberStatus
+
+	// previous is the target weight before the operation
+	previous int64
+	// previousMembers are the member weights before the operation
+	previousMembers map[string]int64
+	// clamped is true when the estimated weight was out of the range of the target
+	clamped bool
+	// estimation explains how the policy decided the desired weight
+	estimation rebalancerv1.Estimation
+	// drifted is true when the target weights differed from the desired ones
+	drifted bool
+	// changed is true when the target weights were updated
+	changed bool
+	// guardFailures are the guards which did not pass
+	guardFailures []guardFailure
+	// held is true when a failed guard kept the target weights as they were
+	held bool
+}
+
+// rebalanceError is returned by a failed rebalance operation and reports
+// which condition of the Rebalance is affected by the failure
+type rebalanceError struct {
+	condition string
+	reason    string
+	err       error
+}
 
-	// rebalance status
-	if desired == actual {
-		status.Condition = rebalancerv1.RebalanceHealty
-	} else if rb.Spec.DryRun {
-		status.Condition = rebalancerv1.RebalanceUnhealthy
-	} else {
-		status.Condition = rebalancerv1.RebalanceError
+func (e *rebalanceError) Error() string {
+	return e.err.Error()
+}
+
+func (e *rebalanceError) Unwrap() error {
+	return e.err
+}
+
+func (r *RebalanceReconciler) updateStatus(ctx context.Context, rb rebalancerv1.Rebalance, result rebalanceResult, rebalanceErr error) error {
+	status := *rb.Status.DeepCopy()
+	status.ObservedGeneration = rb.Generation
+	if result.mode != "" {
+		status.Mode = result.mode
 	}
 
-	// weight
-	status.DesiredValue = desired
-	status.ActualValue = actual
+	if rebalanceErr != nil {
+		// keep the last known weights and report the failure
+		reason := rebalancerv1.ReasonReconcileFailed
+		var rerr *rebalanceError
+		if errors.As(rebalanceErr, &rerr) {
+			reason = rerr.reason
+			if rerr.condition != rebalancerv1.ConditionReady {
+				setCondition(&status, rb.Generation, rerr.condition, metav1.ConditionFalse, rerr.reason, rerr.Error())
+			}
+		}
+		setCondition(&status, rb.Generation, rebalancerv1.ConditionReady, metav1.ConditionFalse, reason, rebalanceErr.Error())
+	} else {
+		if result.mode == rebalancerv1.ModePolicy {
+			if result.estimation.Metric != nil || rb.Spec.Metrics != nil {
+				setCondition(&status, rb.Generation, rebalancerv1.ConditionMetricsAvailable, metav1.ConditionTrue,
+					rebalancerv1.ReasonMetricsFetched, "metrics were fetched successfully")
+			} else if rb.Spec.Metrics == nil {
+				meta.RemoveStatusCondition(&status.Conditions, rebalancerv1.ConditionMetricsAvailable)
+			}
+
+			switch {
+			case len(rb.Spec.Guards) == 0:
+				meta.RemoveStatusCondition(&status.Conditions, rebalancerv1.ConditionGuardsPassed)
+			case len(result.guardFailures) > 0:
+				setCondition(&status, rb.Generation, rebalancerv1.ConditionGuardsPassed, metav1.ConditionFalse,
+					rebalancerv1.ReasonGuardFailed, describeGuardFailures(result.guardFailures))
+			default:
+				setCondition(&status, rb.Generation, rebalancerv1.ConditionGuardsPassed, metav1.ConditionTrue,
+					rebalancerv1.ReasonGuardsPassed, "all guards passed")
+			}
+		}
+		setCondition(&status, rb.Generation, rebalancerv1.ConditionTargetReachable, metav1.ConditionTrue,
+			rebalancerv1.ReasonTargetReached, "target weight was read successfully")
+
+		// rebalance status
+		switch {
+		case result.mode == rebalancerv1.ModeSuspended:
+			setCondition(&status, rb.Generation, rebalancerv1.ConditionInSync, metav1.ConditionUnknown,
+				rebalancerv1.ReasonSuspended, describeMode(rb, result.mode))
+		case result.desired == result.actual && membersInSync(result.members):
+			setCondition(&status, rb.Generation, rebalancerv1.ConditionInSync, metav1.ConditionTrue,
+				rebalancerv1.ReasonInSync, fmt.Sprintf("target weight is %d", result.actual))
+		case rb.Spec.DryRun:
+			setCondition(&status, rb.Generation, rebalancerv1.ConditionInSync, metav1.ConditionFalse,
+				rebalancerv1.ReasonDryRun, fmt.Sprintf("dry-run: target weight %d is not updated to %d", result.actual, result.desired))
+		default:
+			setCondition(&status, rb.Generation, rebalancerv1.ConditionInSync, metav1.ConditionFalse,
+				rebalancerv1.ReasonOutOfSync, fmt.Sprintf("target weight %d differs from desired weight %d", result.actual, result.desired))
+		}
+		setCondition(&status, rb.Generation, rebalancerv1.ConditionReady, metav1.ConditionTrue,
+			rebalancerv1.ReasonReconciled, "rebalance operation succeeded")
+
+		// weight
+		status.ActualValue = result.actual
+		if result.mode != rebalancerv1.ModeSuspended {
+			status.DesiredValue = result.desired
+			status.IdealValue = result.desired
+			if result.estimation.Ideal != nil {
+				status.IdealValue = *result.estimation.Ideal
+			}
+			if !result.held {
+				status.Members = result.members
+			}
+			status.Explanation = result.estimation.String()
+		}
+		if result.mode == rebalancerv1.ModePolicy {
+			status.Recommendations = result.estimation.Recommendations
+			// a held weight must not wind up the pid state
+			if !result.held {
+				status.PIDState = result.estimation.PIDState
+			}
+		}
+		if result.changed {
+			now := metav1.Now()
+			status.LastScaleTime = &now
+		}
+
+		if result.drifted {
+			status.History = appendHistory(status.History, newDecision(rb, result), historyLimit(rb))
+		}
+	}
 
 	// update
-	if rb.Status != status {
-		r.setMetrics(rb)
+	if !equality.Semantic.DeepEqual(rb.Status, status) {
 		status.LastUpdateAt = time.Now().Format(time.RFC3339)
 		rb.Status = status
+		r.setMetrics(rb)
 		err := r.Status().Update(ctx, &rb)
 		if err != nil {
 			return err
 		}
 	}
 
-	if rb.Status.Condition != rebalancerv1.RebalanceUnhealthy &&
-		rb.Status.Condition != rebalancerv1.RebalanceHealty {
-		return nil
-	}
 	return nil
 }
 
-func (r *RebalanceReconciler) rebalance(ctx context.Context, rb rebalancerv1.Rebalance, c client.Client) (desired int64, actual int64, e error) {
-	// get metrics client
-	metrics, err := rebalancerv1.GetMetrics(rb)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failes to get metrics: %w", err)
-	}
-	metricsClient, err := metrics.NewClient(ctx, rb)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to initialize metrics client")
+func setCondition(status *rebalancerv1.RebalanceStatus, generation int64, condType string, s metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               condType,
+		Status:             s,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+func membersInSync(members []rebalancerv1.MemberStatus) bool {
+	for _, m := range members {
+		if m.DesiredValue != m.ActualValue {
+			return false
+		}
 	}
+	return true
+}
+
+func (r *RebalanceReconciler) rebalance(ctx context.Context, rb *rebalancerv1.Rebalance, c client.Client, mode string) (rebalanceResult, error) {
+	result := rebalanceResult{mode: mode}
 
 	// get target client
-	target, err := rebalancerv1.GetTarget(rb)
+	target, err := rebalancerv1.GetTarget(*rb)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to get target: %w", err)
+		return result, targetError(fmt.Errorf("failed to get target: %w", err))
 	}
-	targetClient, err := target.NewClient(ctx, rb, c)
+	targetClient, err := target.NewClient(ctx, *rb, c)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to initialize target client: %w", err)
+		return result, targetError(fmt.Errorf("failed to initialize target client: %w", err))
 	}
 
-	// get policy
-	p, err := rebalancerv1.GetPolicy(rb)
+	// get target actual value
+	result.previous, err = targetClient.GetWeight(ctx)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to get policy: %w", err)
+		return result, targetError(fmt.Errorf("failed get current value: %w", err))
 	}
-	policy, err := p.New(&rb, &targetClient, &metricsClient)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to initialize policy")
+	if mode == rebalancerv1.ModeSuspended {
+		result.actual = result.previous
+		return result, nil
 	}
 
 	// estimate target val
-	desired, err = policy.Estimate(ctx)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to estimate targeet value: %w", err)
+	var estimation rebalancerv1.Estimation
+	if mode == rebalancerv1.ModeOverride {
+		estimation = overrideEstimation(rb.Spec.Override)
+	} else {
+		estimation, err = r.estimate(ctx, rb, c, targetClient)
+		if err != nil {
+			return result, err
+		}
+
+		// guards must pass before the policy changes the weight
+		result.guardFailures = checkGuards(ctx, rb.Spec.Guards, guardMetricsClient(ctx, *rb, c))
+		result.held = applyGuardAction(*rb, &estimation, result.previous, result.guardFailures)
 	}
 
-	// get target actual value
-	actual, err = targetClient.GetWeight(ctx)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed get current value: %w", err)
+	// keep the weight within the range the target accepts
+	if min, max := targetClient.WeightRange(); estimation.Value < min || estimation.Value > max {
+		clamped := estimation.Value
+		if clamped < min {
+			clamped = min
+		} else {
+			clamped = max
+		}
+		estimation.AddStep("clamp", estimation.Value, clamped, "range=%d-%d", min, max)
+		estimation.Value = clamped
+		result.clamped = true
 	}
 
+	log.FromContext(ctx).Info("estimated target value", "value", estimation.Value, "explanation", estimation.String())
+	desired := estimation.Value
+	result.desired = desired
+	result.estimation = estimation
+
 	// set weight
-	if actual != desired && !rb.Spec.DryRun {
-		err = targetClient.SetWeight(ctx, desired)
+	groupClient, isGroup := targetClient.(rebalancerv1.GroupTargetClient)
+	switch {
+	case result.held:
+		// a failed guard keeps every weight as it is
+	case isGroup:
+		err = rebalanceGroup(ctx, groupClient, &result, rb.Spec.DryRun)
 		if err != nil {
-			return 0, 0, fmt.Errorf("failed to set target value: %w", err)
+			return result, targetError(err)
+		}
+	case result.previous != desired:
+		result.drifted = true
+		if !rb.Spec.DryRun {
+			err = targetClient.SetWeight(ctx, desired)
+			if err != nil {
+				return result, targetError(fmt.Errorf("failed to set target value: %w", err))
+			}
+			result.changed = true
 		}
 	}
 
 	// get target actual value
-	actual, err = targetClient.GetWeight(ctx)
+	result.actual, err = targetClient.GetWeight(ctx)
+	if err != nil {
+		return result, targetError(fmt.Errorf("failed get current value: %w", err))
+	}
+
+	return result, nil
+}
+
+// estimate asks the policy for the desired weight of the target
+func (r *RebalanceReconciler) estimate(ctx context.Context, rb *rebalancerv1.Rebalance, c client.Client, targetClient rebalancerv1.TargetClient) (rebalancerv1.Estimation, error) {
+	// get policy
+	p, err := rebalancerv1.GetPolicy(*rb)
+	if err != nil {
+		return rebalancerv1.Estimation{}, policyError(fmt.Errorf("failed to get policy: %w", err))
+	}
+
+	// get metrics client
+	var metricsClient rebalancerv1.MetricsClient
+	if rebalancerv1.NeedsMetrics(p, *rb) {
+		metricsClient, err = rebalancerv1.NewMetricsClient(ctx, *rb, c)
+		if err != nil {
+			return rebalancerv1.Estimation{}, metricsError(err)
+		}
+	}
+
+	policy, err := p.New(ctx, rb, c, &targetClient, &metricsClient)
+	if err != nil {
+		return rebalancerv1.Estimation{}, estimationError(fmt.Errorf("failed to initialize policy: %w", err))
+	}
+
+	estimation, err := policy.Estimate(ctx)
+	if err != nil {
+		return estimation, estimationError(fmt.Errorf("failed to estimate targeet value: %w", err))
+	}
+	return estimation, nil
+}
+
+// estimationError reports the failures of the metrics used by the policy as
+// metrics errors and the others as policy errors
+func estimationError(err error) error {
+	var me *rebalancerv1.MetricsError
+	if errors.As(err, &me) {
+		return metricsError(err)
+	}
+	return policyError(err)
+}
+
+func metricsError(err error) error {
+	return &rebalanceError{rebalancerv1.ConditionMetricsAvailable, rebalancerv1.ReasonMetricsError, err}
+}
+
+func targetError(err error) error {
+	return &rebalanceError{rebalancerv1.ConditionTargetReachable, rebalancerv1.ReasonTargetError, err}
+}
+
+func policyError(err error) error {
+	return &rebalanceError{rebalancerv1.ConditionReady, rebalancerv1.ReasonPolicyError, err}
+}
+
+// rebalanceGroup distributes the desired value between the members of the
+// group and applies every member weight at once when any of them differs.
+func rebalanceGroup(ctx context.Context, c rebalancerv1.GroupTargetClient, result *rebalanceResult, dryRun bool) error {
+	desiredWeights, err := c.Distribute(result.desired)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed get current value: %w", err)
+		return fmt.Errorf("failed to distribute target value: %w", err)
 	}
 
-	return desired, actual, nil
+	actualWeights, err := c.GetWeights(ctx)
+	if err != nil {
+		return fmt.Errorf("failed get current values: %w", err)
+	}
+	result.previousMembers = actualWeights
+
+	if !reflect.DeepEqual(desiredWeights, actualWeights) {
+		result.drifted = true
+		if !dryRun {
+			err = c.SetWeights(ctx, desiredWeights)
+			if err != nil {
+				return fmt.Errorf("failed to set target values: %w", err)
+			}
+			result.changed = true
+			actualWeights, err = c.GetWeights(ctx)
+			if err != nil {
+				return fmt.Errorf("failed get current values: %w", err)
+			}
+		}
+	}
+
+	members := make([]rebalancerv1.MemberStatus, 0, len(desiredWeights))
+	for id, w := range desiredWeights {
+		members = append(members, rebalancerv1.MemberStatus{
+			Identifier:   id,
+			DesiredValue: w,
+			ActualValue:  actualWeights[id],
+		})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Identifier < members[j].Identifier
+	})
+	result.members = members
+	return nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -197,27 +476,30 @@ func (r *RebalanceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 }
 
 func (r *RebalanceReconciler) setMetrics(rb rebalancerv1.Rebalance) {
-	switch rb.Status.Condition {
-	case rebalancerv1.RebalanceError:
-		ErrorVec.WithLabelValues(rb.Name, rb.Namespace).Set(1)
-		UnhealthyVec.WithLabelValues(rb.Name, rb.Namespace).Set(0)
-		HealthyVec.WithLabelValues(rb.Name, rb.Namespace).Set(0)
-	case rebalancerv1.RebalanceUnhealthy:
-		ErrorVec.WithLabelValues(rb.Name, rb.Namespace).Set(0)
-		UnhealthyVec.WithLabelValues(rb.Name, rb.Namespace).Set(1)
-		HealthyVec.WithLabelValues(rb.Name, rb.Namespace).Set(0)
-	case rebalancerv1.RebalanceHealty:
-		ErrorVec.WithLabelValues(rb.Name, rb.Namespace).Set(0)
-		UnhealthyVec.WithLabelValues(rb.Name, rb.Namespace).Set(0)
-		HealthyVec.WithLabelValues(rb.Name, rb.Namespace).Set(1)
+	var healthy, unhealthy, failed float64
+	switch {
+	case !meta.IsStatusConditionTrue(rb.Status.Conditions, rebalancerv1.ConditionReady):
+		failed = 1
+	case meta.IsStatusConditionTrue(rb.Status.Conditions, rebalancerv1.ConditionInSync):
+		healthy = 1
+	case rb.Spec.DryRun || rb.Status.Mode == rebalancerv1.ModeSuspended:
+		unhealthy = 1
+	default:
+		failed = 1
 	}
 
+	ErrorVec.WithLabelValues(rb.Name, rb.Namespace).Set(failed)
+	UnhealthyVec.WithLabelValues(rb.Name, rb.Namespace).Set(unhealthy)
+	HealthyVec.WithLabelValues(rb.Name, rb.Namespace).Set(healthy)
+
 	DesiredValVec.WithLabelValues(rb.Name, rb.Namespace).Set(float64(rb.Status.DesiredValue))
 	ActualValVec.WithLabelValues(rb.Name, rb.Namespace).Set(float64(rb.Status.ActualValue))
 }
 
-func (r *RebalanceReconciler) removeMetrics(rb rebalancerv1.Rebalance) {
-	ErrorVec.DeleteLabelValues(rb.Name, rb.Namespace)
-	UnhealthyVec.DeleteLabelValues(rb.Name, rb.Namespace)
-	HealthyVec.DeleteLabelValues(rb.Name, rb.Namespace)
+func (r *RebalanceReconciler) removeMetrics(name types.NamespacedName) {
+	ErrorVec.DeleteLabelValues(name.Name, name.Namespace)
+	UnhealthyVec.DeleteLabelValues(name.Name, name.Namespace)
+	HealthyVec.DeleteLabelValues(name.Name, name.Namespace)
+	DesiredValVec.DeleteLabelValues(name.Name, name.Namespace)
+	ActualValVec.DeleteLabelValues(name.Name, name.Namespace)
 }
@@ -1 +1,238 @@
 package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+func TestUpdateStatus(t *testing.T) {
+	type condition struct {
+		status  metav1.ConditionStatus
+		reason  string
+		message string
+	}
+	type gauges struct {
+		failed, unhealthy, healthy float64
+	}
+
+	tests := []struct {
+		name      string
+		dryRun    bool
+		result    rebalanceResult
+		err       error
+		wantReady condition
+		// wantInSync is the InSync condition, if any. Empty messages are not checked
+		wantInSync *condition
+		// wantTarget is the TargetReachable condition, if any
+		wantTarget *condition
+		wantGauges gauges
+	}{
+		{
+			name:       "in sync",
+			result:     rebalanceResult{mode: rebalancerv1.ModePolicy, desired: 20, actual: 20},
+			wantReady:  condition{metav1.ConditionTrue, rebalancerv1.ReasonReconciled, "rebalance operation succeeded"},
+			wantInSync: &condition{metav1.ConditionTrue, rebalancerv1.ReasonInSync, "target weight is 20"},
+			wantTarget: &condition{metav1.ConditionTrue, rebalancerv1.ReasonTargetReached, "target weight was read successfully"},
+			wantGauges: gauges{healthy: 1},
+		},
+		{
+			name:       "out of sync",
+			result:     rebalanceResult{mode: rebalancerv1.ModePolicy, desired: 20, actual: 10},
+			wantReady:  condition{metav1.ConditionTrue, rebalancerv1.ReasonReconciled, "rebalance operation succeeded"},
+			wantInSync: &condition{metav1.ConditionFalse, rebalancerv1.ReasonOutOfSync, "target weight 10 differs from desired weight 20"},
+			wantGauges: gauges{failed: 1},
+		},
+		{
+			name:       "dry-run",
+			dryRun:     true,
+			result:     rebalanceResult{mode: rebalancerv1.ModePolicy, desired: 20, actual: 10},
+			wantReady:  condition{metav1.ConditionTrue, rebalancerv1.ReasonReconciled, "rebalance operation succeeded"},
+			wantInSync: &condition{metav1.ConditionFalse, rebalancerv1.ReasonDryRun, "dry-run: target weight 10 is not updated to 20"},
+			wantGauges: gauges{unhealthy: 1},
+		},
+		{
+			name:       "suspended",
+			result:     rebalanceResult{mode: rebalancerv1.ModeSuspended, actual: 10},
+			wantReady:  condition{metav1.ConditionTrue, rebalancerv1.ReasonReconciled, "rebalance operation succeeded"},
+			wantInSync: &condition{metav1.ConditionUnknown, rebalancerv1.ReasonSuspended, ""},
+			wantGauges: gauges{unhealthy: 1},
+		},
+		{
+			name:       "target error",
+			result:     rebalanceResult{mode: rebalancerv1.ModePolicy},
+			err:        targetError(errors.New("access denied")),
+			wantReady:  condition{metav1.ConditionFalse, rebalancerv1.ReasonTargetError, "access denied"},
+			wantTarget: &condition{metav1.ConditionFalse, rebalancerv1.ReasonTargetError, "access denied"},
+			wantGauges: gauges{failed: 1},
+		},
+		{
+			name:       "unclassified error",
+			err:        errors.New("boom"),
+			wantReady:  condition{metav1.ConditionFalse, rebalancerv1.ReasonReconcileFailed, "boom"},
+			wantGauges: gauges{failed: 1},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := rebalancerv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rb := rebalancerv1.Rebalance{
+				ObjectMeta: metav1.ObjectMeta{Name: "status-" + tt.name, Namespace: "default", Generation: 3},
+				Spec:       rebalancerv1.RebalanceSpec{DryRun: tt.dryRun},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&rb).Build()
+			r := &RebalanceReconciler{Client: c, Scheme: scheme}
+
+			if err := r.updateStatus(context.Background(), rb, tt.result, tt.err); err != nil {
+				t.Fatal(err)
+			}
+			var got rebalancerv1.Rebalance
+			if err := c.Get(context.Background(), client.ObjectKeyFromObject(&rb), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Status.ObservedGeneration != 3 {
+				t.Errorf("observedGeneration = %d, want 3", got.Status.ObservedGeneration)
+			}
+			check := func(condType string, want *condition) {
+				if want == nil {
+					return
+				}
+				cond := meta.FindStatusCondition(got.Status.Conditions, condType)
+				if cond == nil {
+					t.Errorf("condition %s is missing", condType)
+					return
+				}
+				if cond.Status != want.status || cond.Reason != want.reason ||
+					(want.message != "" && cond.Message != want.message) {
+					t.Errorf("condition %s = %s %s %q, want %s %s %q", condType,
+						cond.Status, cond.Reason, cond.Message, want.status, want.reason, want.message)
+				}
+				if cond.ObservedGeneration != 3 {
+					t.Errorf("condition %s observedGeneration = %d, want 3", condType, cond.ObservedGeneration)
+				}
+			}
+			check(rebalancerv1.ConditionReady, &tt.wantReady)
+			check(rebalancerv1.ConditionInSync, tt.wantInSync)
+			check(rebalancerv1.ConditionTargetReachable, tt.wantTarget)
+
+			labels := []string{rb.Name, rb.Namespace}
+			gotGauges := gauges{
+				failed:    testutil.ToFloat64(ErrorVec.WithLabelValues(labels...)),
+				unhealthy: testutil.ToFloat64(UnhealthyVec.WithLabelValues(labels...)),
+				healthy:   testutil.ToFloat64(HealthyVec.WithLabelValues(labels...)),
+			}
+			if gotGauges != tt.wantGauges {
+				t.Errorf("gauges = %+v, want %+v", gotGauges, tt.wantGauges)
+			}
+			if v := testutil.ToFloat64(ActualValVec.WithLabelValues(labels...)); v != float64(tt.result.actual) {
+				t.Errorf("actual gauge = %v, want %v", v, tt.result.actual)
+			}
+			if v := testutil.ToFloat64(DesiredValVec.WithLabelValues(labels...)); v != float64(tt.result.desired) {
+				t.Errorf("desired gauge = %v, want %v", v, tt.result.desired)
+			}
+		})
+	}
+}
+
+type stubTargetClient struct{ weight int64 }
+
+func (s *stubTargetClient) GetWeight(ctx context.Context) (int64, error) { return s.weight, nil }
+func (s *stubTargetClient) SetWeight(ctx context.Context, value int64) error {
+	s.weight = value
+	return nil
+}
+func (s *stubTargetClient) WeightRange() (int64, int64) { return 0, 255 }
+
+// TestEstimateMetricsError checks that failing queries of the metrics the
+// policy uses are reported as metrics errors
+func TestEstimateMetricsError(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	failingMetrics := rebalancerv1.RebalanceMetrics{
+		Prometheus: &rebalancerv1.PrometheusMetrics{Address: failing.URL, Query: "errors"},
+	}
+
+	tests := []struct {
+		name string
+		spec rebalancerv1.RebalanceSpec
+	}{
+		{
+			name: "named metrics",
+			spec: rebalancerv1.RebalanceSpec{
+				Policy: rebalancerv1.RebalancePolicy{
+					TargetTracking: &rebalancerv1.TargetTrackingPolicy{TargetValue: 100, BaseValue: 10, Metric: "errors"},
+				},
+				NamedMetrics: []rebalancerv1.NamedMetrics{{Name: "errors", Source: failingMetrics}},
+			},
+		},
+		{
+			name: "composite child metrics",
+			spec: rebalancerv1.RebalanceSpec{
+				Policy: rebalancerv1.RebalancePolicy{
+					Composite: &rebalancerv1.CompositePolicy{
+						Operator: rebalancerv1.CompositeMax,
+						Policies: []rebalancerv1.CompositeChild{
+							{
+								Name:    "errors",
+								Policy:  rebalancerv1.ChildPolicy{TargetTracking: &rebalancerv1.TargetTrackingPolicy{TargetValue: 100, BaseValue: 10}},
+								Metrics: &failingMetrics,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := rebalancerv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rb := rebalancerv1.Rebalance{
+				ObjectMeta: metav1.ObjectMeta{Name: "estimate", Namespace: "default"},
+				Spec:       tt.spec,
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&rb).Build()
+			r := &RebalanceReconciler{Client: c, Scheme: scheme}
+
+			_, err := r.estimate(context.Background(), &rb, c, &stubTargetClient{weight: 10})
+			if err == nil {
+				t.Fatal("estimate succeeded, want a metrics error")
+			}
+			if err := r.updateStatus(context.Background(), rb, rebalanceResult{mode: rebalancerv1.ModePolicy}, err); err != nil {
+				t.Fatal(err)
+			}
+			var got rebalancerv1.Rebalance
+			if err := c.Get(context.Background(), client.ObjectKeyFromObject(&rb), &got); err != nil {
+				t.Fatal(err)
+			}
+			cond := meta.FindStatusCondition(got.Status.Conditions, rebalancerv1.ConditionMetricsAvailable)
+			if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != rebalancerv1.ReasonMetricsError {
+				t.Errorf("condition %s = %+v, want %s %s", rebalancerv1.ConditionMetricsAvailable, cond,
+					metav1.ConditionFalse, rebalancerv1.ReasonMetricsError)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetValue returns the value of the key referenced by the selector in
+// namespace. Selectors referring to another namespace are rejected.
+func GetValue(ctx context.Context, c client.Client, ref rebalancerv1.SecretKeySelector, namespace string) ([]byte, error) {
+	if ref.Namespace != nil && *ref.Namespace != namespace {
+		return nil, fmt.Errorf("secret %s/%s is not in namespace %s", *ref.Namespace, ref.Name, namespace)
+	}
+	ke := client.ObjectKey{
+		Name:      ref.Name,
+		Namespace: namespace,
+	}
+	s := v1.Secret{}
+	err := c.Get(ctx, ke, &s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s: %w", ke, err)
+	}
+
+	val, ok := s.Data[ref.Key]
+	if !ok || len(val) == 0 {
+		return nil, fmt.Errorf("missing key %q in secret %s", ref.Key, ke)
+	}
+	return val, nil
+}
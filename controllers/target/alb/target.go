@@ -0,0 +1,158 @@
+package alb
+
+import (
+	"context"
+	"fmt"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"git.pepabo.com/akichan/rebalancer/controllers/target/awsauth"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// weights of the target groups of a forward action must be within this range
+const (
+	minWeight = 0
+	maxWeight = 999
+)
+
+type Target struct {
+	ruleARN        string
+	targetGroupARN string
+	client         *elbv2.Client
+}
+
+func (t *Target) NewClient(ctx context.Context, r rebalancerv1.Rebalance, c client.Client) (rebalancerv1.TargetClient, error) {
+	spec := r.Spec.Target.ALB
+
+	region, err := ruleRegion(*spec)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := awsauth.LoadConfig(ctx, r, c, spec.Auth, region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Target{
+		ruleARN:        spec.RuleARN,
+		targetGroupARN: spec.TargetGroupARN,
+		client:         elbv2.NewFromConfig(cfg),
+	}, nil
+}
+
+func (t *Target) Validate(r rebalancerv1.Rebalance) error {
+	spec := r.Spec.Target.ALB
+	var errs []error
+
+	if spec.RuleARN == "" {
+		errs = append(errs, fmt.Errorf("ruleARN must not be empty"))
+	} else if _, err := ruleRegion(*spec); err != nil {
+		errs = append(errs, err)
+	}
+	if spec.TargetGroupARN == "" {
+		errs = append(errs, fmt.Errorf("targetGroupARN must not be empty"))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// ruleRegion returns the region of the spec, or the region of the rule ARN
+// when the spec does not specify one
+func ruleRegion(spec rebalancerv1.ALBTarget) (string, error) {
+	if spec.Region != "" {
+		return spec.Region, nil
+	}
+	a, err := arn.Parse(spec.RuleARN)
+	if err != nil {
+		return "", fmt.Errorf("invalid ruleARN %q: %w", spec.RuleARN, err)
+	}
+	return a.Region, nil
+}
+
+func (t *Target) GetWeight(ctx context.Context) (int64, error) {
+	actions, err := t.fetchActions(ctx)
+	if err != nil {
+		return 0, err
+	}
+	tg, err := t.findTargetGroup(actions)
+	if err != nil {
+		return 0, err
+	}
+	return int64(aws.ToInt32(tg.Weight)), nil
+}
+
+// SetWeight modifies the rule with its actions as they are, except for the
+// weight of the target group. DescribeRules does not return the client secret
+// of the OIDC authentication, so the existing secret is kept.
+func (t *Target) SetWeight(ctx context.Context, value int64) error {
+	actions, err := t.fetchActions(ctx)
+	if err != nil {
+		return err
+	}
+	tg, err := t.findTargetGroup(actions)
+	if err != nil {
+		return err
+	}
+	tg.Weight = aws.Int32(int32(value))
+
+	for i := range actions {
+		// the target group of the action must not be set with a forward config of several target groups
+		if actions[i].ForwardConfig != nil {
+			actions[i].TargetGroupArn = nil
+		}
+		if oidc := actions[i].AuthenticateOidcConfig; oidc != nil {
+			oidc.ClientSecret = nil
+			oidc.UseExistingClientSecret = aws.Bool(true)
+		}
+	}
+	_, err = t.client.ModifyRule(ctx, &elbv2.ModifyRuleInput{
+		RuleArn: aws.String(t.ruleARN),
+		Actions: actions,
+	})
+	return err
+}
+
+func (t *Target) WeightRange() (int64, int64) {
+	return minWeight, maxWeight
+}
+
+func (t *Target) fetchActions(ctx context.Context) ([]types.Action, error) {
+	out, err := t.client.DescribeRules(ctx, &elbv2.DescribeRulesInput{
+		RuleArns: []string{t.ruleARN},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Rules) == 0 {
+		return nil, fmt.Errorf("listener rule %s not found", t.ruleARN)
+	}
+	return out.Rules[0].Actions, nil
+}
+
+// findTargetGroup returns the target group of the forward action
+func (t *Target) findTargetGroup(actions []types.Action) (*types.TargetGroupTuple, error) {
+	for i := range actions {
+		a := &actions[i]
+		if a.Type != types.ActionTypeEnumForward || a.ForwardConfig == nil {
+			continue
+		}
+		for j := range a.ForwardConfig.TargetGroups {
+			tg := &a.ForwardConfig.TargetGroups[j]
+			if aws.ToString(tg.TargetGroupArn) == t.targetGroupARN {
+				return tg, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("target group %s not found in the forward action of rule %s", t.targetGroupARN, t.ruleARN)
+}
+
+func init() {
+	rebalancerv1.RegisterTarget(&Target{}, &rebalancerv1.RebalanceTarget{
+		ALB: &rebalancerv1.ALBTarget{},
+	})
+}
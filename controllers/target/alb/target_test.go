@@ -0,0 +1,180 @@
+package alb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+)
+
+const (
+	ruleARN   = "arn:aws:elasticloadbalancing:ap-northeast-1:123456789012:listener-rule/app/web/1/2/3"
+	blueARN   = "arn:aws:elasticloadbalancing:ap-northeast-1:123456789012:targetgroup/blue/1"
+	greenARN  = "arn:aws:elasticloadbalancing:ap-northeast-1:123456789012:targetgroup/green/2"
+	namespace = "http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/"
+)
+
+// stub serves DescribeRules and ModifyRule of a rule forwarding to two target groups
+type stub struct {
+	mu      sync.Mutex
+	weights map[string]int64
+	order   []string
+	// oidc puts an OIDC authentication before the forward action, whose client
+	// secret is not returned by DescribeRules like the API
+	oidc bool
+	// modified are the form values of the last ModifyRule request
+	modified map[string][]string
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch action := r.PostForm.Get("Action"); action {
+	case "DescribeRules":
+		if r.PostForm.Get("RuleArns.member.1") != ruleARN {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `<ErrorResponse xmlns="%s"><Error><Type>Sender</Type><Code>RuleNotFound</Code><Message>not found</Message></Error></ErrorResponse>`, namespace)
+			return
+		}
+		fmt.Fprintf(w, `<DescribeRulesResponse xmlns="%s"><DescribeRulesResult><Rules><member>%s</member></Rules></DescribeRulesResult></DescribeRulesResponse>`, namespace, s.rule())
+	case "ModifyRule":
+		s.modified = r.PostForm
+		forward := 1
+		if s.oidc {
+			if r.PostForm.Get("Actions.member.1.AuthenticateOidcConfig.ClientSecret") == "" &&
+				r.PostForm.Get("Actions.member.1.AuthenticateOidcConfig.UseExistingClientSecret") != "true" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, `<ErrorResponse xmlns="%s"><Error><Type>Sender</Type><Code>ValidationError</Code><Message>client secret is required</Message></Error></ErrorResponse>`, namespace)
+				return
+			}
+			forward = 2
+		}
+		for i := 1; ; i++ {
+			prefix := fmt.Sprintf("Actions.member.%d.ForwardConfig.TargetGroups.member.%d.", forward, i)
+			arn := r.PostForm.Get(prefix + "TargetGroupArn")
+			if arn == "" {
+				break
+			}
+			weight, err := strconv.ParseInt(r.PostForm.Get(prefix+"Weight"), 10, 64)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			s.weights[arn] = weight
+		}
+		fmt.Fprintf(w, `<ModifyRuleResponse xmlns="%s"><ModifyRuleResult><Rules><member>%s</member></Rules></ModifyRuleResult></ModifyRuleResponse>`, namespace, s.rule())
+	default:
+		http.Error(w, "unexpected action "+action, http.StatusBadRequest)
+	}
+}
+
+func (s *stub) rule() string {
+	var b strings.Builder
+	for _, arn := range s.order {
+		fmt.Fprintf(&b, "<member><TargetGroupArn>%s</TargetGroupArn><Weight>%d</Weight></member>", arn, s.weights[arn])
+	}
+	var auth string
+	if s.oidc {
+		auth = `<member><Type>authenticate-oidc</Type><Order>1</Order><AuthenticateOidcConfig>` +
+			`<Issuer>https://idp.example.com</Issuer><AuthorizationEndpoint>https://idp.example.com/authorize</AuthorizationEndpoint>` +
+			`<TokenEndpoint>https://idp.example.com/token</TokenEndpoint><UserInfoEndpoint>https://idp.example.com/userinfo</UserInfoEndpoint>` +
+			`<ClientId>rebalancer</ClientId></AuthenticateOidcConfig></member>`
+	}
+	return fmt.Sprintf(`<RuleArn>%s</RuleArn><Priority>1</Priority><Actions>%s<member><Type>forward</Type><TargetGroupArn>%s</TargetGroupArn>`+
+		`<ForwardConfig><TargetGroups>%s</TargetGroups></ForwardConfig></member></Actions>`, ruleARN, auth, s.order[0], b.String())
+}
+
+func newTarget(url, targetGroupARN string) *Target {
+	return &Target{
+		ruleARN:        ruleARN,
+		targetGroupARN: targetGroupARN,
+		client: elbv2.New(elbv2.Options{
+			Region:           "ap-northeast-1",
+			Credentials:      credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+			EndpointResolver: elbv2.EndpointResolverFromURL(url),
+		}),
+	}
+}
+
+func TestSetWeight(t *testing.T) {
+	s := &stub{
+		weights: map[string]int64{blueARN: 90, greenARN: 10},
+		order:   []string{blueARN, greenARN},
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	target := newTarget(srv.URL, greenARN)
+	ctx := context.Background()
+
+	got, err := target.GetWeight(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 10 {
+		t.Errorf("GetWeight() = %v, want 10", got)
+	}
+
+	if err := target.SetWeight(ctx, 40); err != nil {
+		t.Fatal(err)
+	}
+	if s.weights[greenARN] != 40 || s.weights[blueARN] != 90 {
+		t.Errorf("weights = %v, want green 40 and blue 90", s.weights)
+	}
+	if _, ok := s.modified["Actions.member.1.TargetGroupArn"]; ok {
+		t.Errorf("ModifyRule must not send the target group of the action with a forward config")
+	}
+
+	got, err = target.GetWeight(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 40 {
+		t.Errorf("GetWeight() = %v, want 40", got)
+	}
+}
+
+func TestSetWeightWithOIDC(t *testing.T) {
+	s := &stub{
+		weights: map[string]int64{blueARN: 90, greenARN: 10},
+		order:   []string{blueARN, greenARN},
+		oidc:    true,
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	target := newTarget(srv.URL, greenARN)
+
+	if err := target.SetWeight(context.Background(), 40); err != nil {
+		t.Fatal(err)
+	}
+	if s.weights[greenARN] != 40 || s.weights[blueARN] != 90 {
+		t.Errorf("weights = %v, want green 40 and blue 90", s.weights)
+	}
+	if got := s.modified["Actions.member.1.Type"]; len(got) != 1 || got[0] != "authenticate-oidc" {
+		t.Errorf("ModifyRule must keep the authentication action, got type %v", got)
+	}
+}
+
+func TestTargetGroupNotFound(t *testing.T) {
+	s := &stub{
+		weights: map[string]int64{blueARN: 100},
+		order:   []string{blueARN},
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	if _, err := newTarget(srv.URL, greenARN).GetWeight(context.Background()); err == nil {
+		t.Errorf("GetWeight() must fail when the target group is not in the rule")
+	}
+}
@@ -0,0 +1,47 @@
+package awsauth
+
+import (
+	"context"
+	"fmt"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"git.pepabo.com/akichan/rebalancer/controllers/secret"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// LoadConfig returns the AWS config of the region. The credentials are read
+// from the secrets of the auth, or resolved by the default credential chain
+// when the auth has no secret reference.
+func LoadConfig(ctx context.Context, r rebalancerv1.Rebalance, c client.Client, auth rebalancerv1.AWSAuth, region string) (aws.Config, error) {
+	optFns := []func(*config.LoadOptions) error{config.WithRegion(region)}
+
+	// secret ref option
+	if auth.SecretRef != nil {
+		cred, err := credFromSecretRef(ctx, r, c, *auth.SecretRef)
+		if err != nil {
+			return aws.Config{}, err
+		}
+		optFns = append(optFns, config.WithCredentialsProvider(cred))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, optFns...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("load config error: %w", err)
+	}
+	return cfg, nil
+}
+
+func credFromSecretRef(ctx context.Context, r rebalancerv1.Rebalance, c client.Client, secRef rebalancerv1.AWSAuthSecretRef) (credentials.StaticCredentialsProvider, error) {
+	ak, err := secret.GetValue(ctx, c, secRef.AccessKeyID, r.Namespace)
+	if err != nil {
+		return credentials.StaticCredentialsProvider{}, fmt.Errorf("failed to get access key id: %w", err)
+	}
+	sak, err := secret.GetValue(ctx, c, secRef.SecretAccessKey, r.Namespace)
+	if err != nil {
+		return credentials.StaticCredentialsProvider{}, fmt.Errorf("failed to get secret access key: %w", err)
+	}
+	return credentials.NewStaticCredentialsProvider(string(ak), string(sak), ""), nil
+}
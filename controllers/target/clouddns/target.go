@@ -0,0 +1,153 @@
+package clouddns
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"git.pepabo.com/akichan/rebalancer/controllers/secret"
+	"google.golang.org/api/dns/v1"
+	"google.golang.org/api/option"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Cloud DNS weights are non-negative numbers, rebalanced as integers within this range
+const (
+	minWeight = 0
+	maxWeight = 1000
+)
+
+type Target struct {
+	project     string
+	managedZone string
+	name        string
+	rrType      string
+	rrdata      string
+	service     *dns.Service
+}
+
+func (t *Target) NewClient(ctx context.Context, r rebalancerv1.Rebalance, c client.Client) (rebalancerv1.TargetClient, error) {
+	spec := r.Spec.Target.CloudDNS
+
+	opts := []option.ClientOption{option.WithScopes(dns.NdevClouddnsReadwriteScope)}
+	if ref := spec.Auth.ServiceAccountKey; ref != nil {
+		key, err := secret.GetValue(ctx, c, *ref, r.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get service account key: %w", err)
+		}
+		opts = append(opts, option.WithCredentialsJSON(key))
+	}
+	service, err := dns.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cloud dns client: %w", err)
+	}
+
+	return &Target{
+		project:     spec.Project,
+		managedZone: spec.ManagedZone,
+		name:        fqdn(spec.Name),
+		rrType:      spec.Type,
+		rrdata:      spec.Rrdata,
+		service:     service,
+	}, nil
+}
+
+func (t *Target) Validate(r rebalancerv1.Rebalance) error {
+	spec := r.Spec.Target.CloudDNS
+	var errs []error
+
+	if spec.Project == "" {
+		errs = append(errs, fmt.Errorf("project must not be empty"))
+	}
+	if spec.ManagedZone == "" {
+		errs = append(errs, fmt.Errorf("managedZone must not be empty"))
+	}
+	if spec.Name == "" || spec.Type == "" {
+		errs = append(errs, fmt.Errorf("name and type must not be empty"))
+	}
+	if spec.Rrdata == "" {
+		errs = append(errs, fmt.Errorf("rrdata must not be empty"))
+	}
+	if ref := spec.Auth.ServiceAccountKey; ref != nil && (ref.Name == "" || ref.Key == "") {
+		errs = append(errs, fmt.Errorf("serviceAccountKeySecretRef name and key must not be empty"))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (t *Target) GetWeight(ctx context.Context) (int64, error) {
+	rr, err := t.fetchRecordSet(ctx)
+	if err != nil {
+		return 0, err
+	}
+	item, err := t.findItem(rr)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(item.Weight)), nil
+}
+
+// SetWeight patches the record set with its routing policy as it is, except
+// for the weight of the item
+func (t *Target) SetWeight(ctx context.Context, value int64) error {
+	rr, err := t.fetchRecordSet(ctx)
+	if err != nil {
+		return err
+	}
+	item, err := t.findItem(rr)
+	if err != nil {
+		return err
+	}
+	item.Weight = float64(value)
+
+	for _, i := range rr.RoutingPolicy.Wrr.Items {
+		// zero weights are omitted unless they are forced
+		i.ForceSendFields = append(i.ForceSendFields, "Weight")
+	}
+	_, err = t.service.ResourceRecordSets.Patch(t.project, t.managedZone, t.name, t.rrType, rr).Context(ctx).Do()
+	return err
+}
+
+func (t *Target) WeightRange() (int64, int64) {
+	return minWeight, maxWeight
+}
+
+func (t *Target) fetchRecordSet(ctx context.Context) (*dns.ResourceRecordSet, error) {
+	rr, err := t.service.ResourceRecordSets.Get(t.project, t.managedZone, t.name, t.rrType).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	if rr.RoutingPolicy == nil || rr.RoutingPolicy.Wrr == nil {
+		return nil, fmt.Errorf("record set %s %s has no weighted round robin routing policy", t.name, t.rrType)
+	}
+	return rr, nil
+}
+
+// findItem returns the routing policy item serving the rrdata
+func (t *Target) findItem(rr *dns.ResourceRecordSet) (*dns.RRSetRoutingPolicyWrrPolicyWrrPolicyItem, error) {
+	for _, item := range rr.RoutingPolicy.Wrr.Items {
+		for _, d := range item.Rrdatas {
+			if d == t.rrdata {
+				return item, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("rrdata %s not found in the routing policy of record set %s %s", t.rrdata, t.name, t.rrType)
+}
+
+// fqdn returns the record name with the trailing dot used by Cloud DNS
+func fqdn(name string) string {
+	if !strings.HasSuffix(name, ".") {
+		return name + "."
+	}
+	return name
+}
+
+func init() {
+	rebalancerv1.RegisterTarget(&Target{}, &rebalancerv1.RebalanceTarget{
+		CloudDNS: &rebalancerv1.CloudDNSTarget{},
+	})
+}
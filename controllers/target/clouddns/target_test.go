@@ -0,0 +1,122 @@
+package clouddns
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"google.golang.org/api/dns/v1"
+	"google.golang.org/api/option"
+)
+
+const path = "/dns/v1/projects/project/managedZones/zone/rrsets/www.example.com./A"
+
+// stub serves Get and Patch of a record set with a weighted round robin routing policy
+type stub struct {
+	mu sync.Mutex
+	rr dns.ResourceRecordSet
+	// patched is the body of the last Patch request
+	patched map[string]interface{}
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path != path {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": map[string]interface{}{"code": 404, "message": "not found"},
+		})
+		return
+	}
+
+	if r.Method == http.MethodPatch {
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.patched = nil
+		s.rr = dns.ResourceRecordSet{}
+		if err := json.Unmarshal(body, &s.patched); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := json.Unmarshal(body, &s.rr); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&s.rr)
+}
+
+func newTarget(t *testing.T, url, rrdata string) *Target {
+	service, err := dns.NewService(context.Background(),
+		option.WithEndpoint(url),
+		option.WithHTTPClient(http.DefaultClient),
+		option.WithoutAuthentication(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Target{
+		project:     "project",
+		managedZone: "zone",
+		name:        "www.example.com.",
+		rrType:      "A",
+		rrdata:      rrdata,
+		service:     service,
+	}
+}
+
+func TestSetWeight(t *testing.T) {
+	s := &stub{rr: dns.ResourceRecordSet{
+		Name: "www.example.com.",
+		Type: "A",
+		Ttl:  60,
+		RoutingPolicy: &dns.RRSetRoutingPolicy{
+			Wrr: &dns.RRSetRoutingPolicyWrrPolicy{
+				Items: []*dns.RRSetRoutingPolicyWrrPolicyWrrPolicyItem{
+					{Rrdatas: []string{"192.0.2.1"}, Weight: 90},
+					{Rrdatas: []string{"192.0.2.2", "192.0.2.3"}, Weight: 10},
+				},
+			},
+		},
+	}}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	target := newTarget(t, srv.URL, "192.0.2.3")
+	ctx := context.Background()
+
+	if got, err := target.GetWeight(ctx); err != nil || got != 10 {
+		t.Errorf("GetWeight() = %v, %v, want 10", got, err)
+	}
+	if err := target.SetWeight(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	items := s.rr.RoutingPolicy.Wrr.Items
+	if items[1].Weight != 0 || items[0].Weight != 90 {
+		t.Errorf("weights = %v and %v, want 90 and 0", items[0].Weight, items[1].Weight)
+	}
+	// the zero weight must be sent rather than omitted
+	item := s.patched["routingPolicy"].(map[string]interface{})["wrr"].(map[string]interface{})["items"].([]interface{})[1]
+	if _, ok := item.(map[string]interface{})["weight"]; !ok {
+		t.Errorf("Patch must send the zero weight")
+	}
+	if s.rr.Ttl != 60 {
+		t.Errorf("ttl = %v, want 60 to be kept", s.rr.Ttl)
+	}
+	if got, err := target.GetWeight(ctx); err != nil || got != 0 {
+		t.Errorf("GetWeight() = %v, %v, want 0", got, err)
+	}
+
+	target.rrdata = "192.0.2.4"
+	if _, err := target.GetWeight(ctx); err == nil {
+		t.Errorf("GetWeight() must fail when the rrdata is not in the routing policy")
+	}
+}
@@ -0,0 +1,55 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// OriginTarget manages the weight of an origin of a pool
+type OriginTarget struct {
+	poolID string
+	origin string
+	api    *cloudflare.API
+}
+
+func (t *OriginTarget) GetWeight(ctx context.Context) (int64, error) {
+	pool, i, err := t.fetch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return toWeight(pool.Origins[i].Weight), nil
+}
+
+// SetWeight updates the pool with the weight of the origin. The weights of the
+// other origins are left untouched.
+func (t *OriginTarget) SetWeight(ctx context.Context, value int64) error {
+	pool, i, err := t.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	pool.Origins[i].Weight = fromWeight(value)
+	if _, err := t.api.ModifyLoadBalancerPool(ctx, pool); err != nil {
+		return fmt.Errorf("failed to modify pool %s: %w", t.poolID, err)
+	}
+	return nil
+}
+
+func (t *OriginTarget) WeightRange() (int64, int64) {
+	return minWeight, maxWeight
+}
+
+// fetch returns the pool and the index of the origin in the pool
+func (t *OriginTarget) fetch(ctx context.Context) (cloudflare.LoadBalancerPool, int, error) {
+	pool, err := t.api.LoadBalancerPoolDetails(ctx, t.poolID)
+	if err != nil {
+		return pool, 0, fmt.Errorf("failed to get pool %s: %w", t.poolID, err)
+	}
+	for i, o := range pool.Origins {
+		if o.Name == t.origin {
+			return pool, i, nil
+		}
+	}
+	return pool, 0, fmt.Errorf("origin %q not found in pool %s", t.origin, t.poolID)
+}
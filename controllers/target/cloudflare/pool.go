@@ -0,0 +1,88 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// defaultPoolWeight is the weight of the pools without weight when the load
+// balancer does not set a default weight
+const defaultPoolWeight = 1
+
+// PoolTarget manages the weight of a pool in the random steering of a load balancer
+type PoolTarget struct {
+	zoneID         string
+	loadBalancerID string
+	poolID         string
+	api            *cloudflare.API
+}
+
+func (t *PoolTarget) GetWeight(ctx context.Context) (int64, error) {
+	lb, err := t.fetch(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rs := lb.RandomSteering
+	if rs == nil {
+		return toWeight(defaultPoolWeight), nil
+	}
+	if w, ok := rs.PoolWeights[t.poolID]; ok {
+		return toWeight(w), nil
+	}
+	if rs.DefaultWeight == 0 {
+		return toWeight(defaultPoolWeight), nil
+	}
+	return toWeight(rs.DefaultWeight), nil
+}
+
+// SetWeight updates the load balancer with the weight of the pool. The weights
+// of the other pools are left untouched.
+func (t *PoolTarget) SetWeight(ctx context.Context, value int64) error {
+	lb, err := t.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	if lb.RandomSteering == nil {
+		lb.RandomSteering = &cloudflare.RandomSteering{}
+	}
+	if lb.RandomSteering.PoolWeights == nil {
+		lb.RandomSteering.PoolWeights = make(map[string]float64)
+	}
+	lb.RandomSteering.PoolWeights[t.poolID] = fromWeight(value)
+
+	if _, err := t.api.ModifyLoadBalancer(ctx, t.zoneID, lb); err != nil {
+		return fmt.Errorf("failed to modify load balancer %s: %w", t.loadBalancerID, err)
+	}
+	return nil
+}
+
+func (t *PoolTarget) WeightRange() (int64, int64) {
+	return minWeight, maxWeight
+}
+
+func (t *PoolTarget) fetch(ctx context.Context) (cloudflare.LoadBalancer, error) {
+	lb, err := t.api.LoadBalancerDetails(ctx, t.zoneID, t.loadBalancerID)
+	if err != nil {
+		return lb, fmt.Errorf("failed to get load balancer %s: %w", t.loadBalancerID, err)
+	}
+	if !containsPool(lb, t.poolID) {
+		return lb, fmt.Errorf("pool %s is not used by load balancer %s", t.poolID, t.loadBalancerID)
+	}
+	return lb, nil
+}
+
+func containsPool(lb cloudflare.LoadBalancer, poolID string) bool {
+	if lb.FallbackPool == poolID {
+		return true
+	}
+	for _, p := range lb.DefaultPools {
+		if p == poolID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package cloudflare
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"git.pepabo.com/akichan/rebalancer/controllers/secret"
+	"github.com/cloudflare/cloudflare-go"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Cloudflare weights from 0 to 1 are mapped from weights from 0 to scale
+const (
+	minWeight = 0
+	maxWeight = 100
+	scale     = 100
+)
+
+// Target builds the client of an origin weight or a pool weight
+type Target struct{}
+
+func (t *Target) NewClient(ctx context.Context, r rebalancerv1.Rebalance, c client.Client) (rebalancerv1.TargetClient, error) {
+	spec := r.Spec.Target.Cloudflare
+
+	token, err := secret.GetValue(ctx, c, spec.APIToken, r.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api token: %w", err)
+	}
+	var opts []cloudflare.Option
+	if spec.AccountID != "" {
+		opts = append(opts, cloudflare.UsingAccount(spec.AccountID))
+	}
+	api, err := cloudflare.NewWithAPIToken(string(token), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cloudflare client: %w", err)
+	}
+
+	switch {
+	case spec.Origin != nil:
+		return &OriginTarget{poolID: spec.Origin.PoolID, origin: spec.Origin.Name, api: api}, nil
+	case spec.Pool != nil:
+		return &PoolTarget{zoneID: spec.Pool.ZoneID, loadBalancerID: spec.Pool.LoadBalancerID, poolID: spec.Pool.PoolID, api: api}, nil
+	default:
+		return nil, fmt.Errorf("either origin or pool must be set")
+	}
+}
+
+func (t *Target) Validate(r rebalancerv1.Rebalance) error {
+	spec := r.Spec.Target.Cloudflare
+	var errs []error
+
+	if (spec.Origin == nil) == (spec.Pool == nil) {
+		errs = append(errs, fmt.Errorf("exactly one of origin and pool must be set"))
+	}
+	if o := spec.Origin; o != nil {
+		if o.PoolID == "" {
+			errs = append(errs, fmt.Errorf("origin poolID must not be empty"))
+		}
+		if o.Name == "" {
+			errs = append(errs, fmt.Errorf("origin name must not be empty"))
+		}
+	}
+	if p := spec.Pool; p != nil {
+		if p.ZoneID == "" || p.LoadBalancerID == "" || p.PoolID == "" {
+			errs = append(errs, fmt.Errorf("pool zoneID, loadBalancerID and poolID must not be empty"))
+		}
+	}
+	if spec.APIToken.Name == "" || spec.APIToken.Key == "" {
+		errs = append(errs, fmt.Errorf("apiTokenSecretRef name and key must not be empty"))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// toWeight converts a Cloudflare weight to the weight of the controller
+func toWeight(w float64) int64 {
+	return int64(math.Round(w * scale))
+}
+
+// fromWeight converts a weight of the controller to a Cloudflare weight
+func fromWeight(v int64) float64 {
+	return float64(v) / scale
+}
+
+func init() {
+	rebalancerv1.RegisterTarget(&Target{}, &rebalancerv1.RebalanceTarget{
+		Cloudflare: &rebalancerv1.CloudflareTarget{},
+	})
+}
@@ -0,0 +1,117 @@
+package cloudflare
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/cloudflare/cloudflare-go"
+)
+
+// stub serves the details and updates of a pool and a load balancer
+type stub struct {
+	mu   sync.Mutex
+	pool cloudflare.LoadBalancerPool
+	lb   cloudflare.LoadBalancer
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var target interface{}
+	switch r.URL.Path {
+	case "/accounts/account/load_balancers/pools/" + s.pool.ID:
+		target = &s.pool
+	case "/zones/zone/load_balancers/" + s.lb.ID:
+		target = &s.lb
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": false,
+			"errors":  []map[string]interface{}{{"code": 1002, "message": "not found"}},
+		})
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(target); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "result": target})
+}
+
+func newAPI(t *testing.T, url string) *cloudflare.API {
+	api, err := cloudflare.NewWithAPIToken("token", cloudflare.BaseURL(url), cloudflare.UsingAccount("account"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
+func TestOriginTarget(t *testing.T) {
+	s := &stub{pool: cloudflare.LoadBalancerPool{
+		ID: "pool",
+		Origins: []cloudflare.LoadBalancerOrigin{
+			{Name: "aws", Address: "aws.example.com", Enabled: true, Weight: 0.5},
+			{Name: "gcp", Address: "gcp.example.com", Enabled: true, Weight: 0.5},
+		},
+	}}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	target := &OriginTarget{poolID: "pool", origin: "gcp", api: newAPI(t, srv.URL)}
+	ctx := context.Background()
+
+	if got, err := target.GetWeight(ctx); err != nil || got != 50 {
+		t.Errorf("GetWeight() = %v, %v, want 50", got, err)
+	}
+	if err := target.SetWeight(ctx, 25); err != nil {
+		t.Fatal(err)
+	}
+	if w := s.pool.Origins[1].Weight; w != 0.25 {
+		t.Errorf("origin weight = %v, want 0.25", w)
+	}
+	if w := s.pool.Origins[0].Weight; w != 0.5 {
+		t.Errorf("weight of the other origin = %v, want 0.5", w)
+	}
+	if got, err := target.GetWeight(ctx); err != nil || got != 25 {
+		t.Errorf("GetWeight() = %v, %v, want 25", got, err)
+	}
+}
+
+func TestPoolTarget(t *testing.T) {
+	s := &stub{lb: cloudflare.LoadBalancer{
+		ID:             "lb",
+		DefaultPools:   []string{"aws", "gcp"},
+		FallbackPool:   "aws",
+		SteeringPolicy: "random",
+	}}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	target := &PoolTarget{zoneID: "zone", loadBalancerID: "lb", poolID: "gcp", api: newAPI(t, srv.URL)}
+	ctx := context.Background()
+
+	// pools without weight have the default weight
+	if got, err := target.GetWeight(ctx); err != nil || got != 100 {
+		t.Errorf("GetWeight() = %v, %v, want 100", got, err)
+	}
+	if err := target.SetWeight(ctx, 30); err != nil {
+		t.Fatal(err)
+	}
+	if w := s.lb.RandomSteering.PoolWeights["gcp"]; w != 0.3 {
+		t.Errorf("pool weight = %v, want 0.3", w)
+	}
+	if got, err := target.GetWeight(ctx); err != nil || got != 30 {
+		t.Errorf("GetWeight() = %v, %v, want 30", got, err)
+	}
+
+	target.poolID = "azure"
+	if _, err := target.GetWeight(ctx); err == nil {
+		t.Errorf("GetWeight() must fail when the pool is not used by the load balancer")
+	}
+}
@@ -0,0 +1,209 @@
+package gatewayapi
+
+import (
+	"context"
+	"fmt"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// routes are read as unstructured objects so that the Gateway API is not a
+// dependency
+var routeGVKs = map[string]schema.GroupVersionKind{
+	rebalancerv1.GatewayAPIHTTPRoute: {Group: "gateway.networking.k8s.io", Version: "v1beta1", Kind: "HTTPRoute"},
+	rebalancerv1.GatewayAPIGRPCRoute: {Group: "gateway.networking.k8s.io", Version: "v1alpha2", Kind: "GRPCRoute"},
+}
+
+// weights of backendRefs must be within this range
+const (
+	minWeight = 0
+	maxWeight = 1000000
+	// defaultWeight is the weight of a backendRef without weight
+	defaultWeight = 1
+)
+
+type Target struct {
+	client   client.Client
+	gvk      schema.GroupVersionKind
+	key      client.ObjectKey
+	rule     int
+	ruleName string
+	backend  string
+}
+
+func (t *Target) NewClient(ctx context.Context, r rebalancerv1.Rebalance, c client.Client) (rebalancerv1.TargetClient, error) {
+	spec := r.Spec.Target.GatewayAPI
+
+	kind := spec.Kind
+	if kind == "" {
+		kind = rebalancerv1.GatewayAPIHTTPRoute
+	}
+	gvk, ok := routeGVKs[kind]
+	if !ok {
+		return nil, fmt.Errorf("unsupported route kind %q", kind)
+	}
+
+	var rule int
+	if spec.Rule != nil {
+		rule = int(*spec.Rule)
+	}
+
+	return &Target{
+		client:   c,
+		gvk:      gvk,
+		key:      client.ObjectKey{Namespace: r.Namespace, Name: spec.Name},
+		rule:     rule,
+		ruleName: spec.RuleName,
+		backend:  spec.Backend,
+	}, nil
+}
+
+func (t *Target) Validate(r rebalancerv1.Rebalance) error {
+	spec := r.Spec.Target.GatewayAPI
+	var errs []error
+
+	if _, ok := routeGVKs[spec.Kind]; spec.Kind != "" && !ok {
+		errs = append(errs, fmt.Errorf("unsupported route kind %q", spec.Kind))
+	}
+	if spec.Name == "" {
+		errs = append(errs, fmt.Errorf("name must not be empty"))
+	}
+	if spec.Backend == "" {
+		errs = append(errs, fmt.Errorf("backend must not be empty"))
+	}
+	if spec.Rule != nil && spec.RuleName != "" {
+		errs = append(errs, fmt.Errorf("rule and ruleName can not be used together"))
+	}
+	if spec.Rule != nil && *spec.Rule < 0 {
+		errs = append(errs, fmt.Errorf("rule must not be negative: %d", *spec.Rule))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (t *Target) GetWeight(ctx context.Context) (int64, error) {
+	route, err := t.fetch(ctx)
+	if err != nil {
+		return 0, err
+	}
+	_, backend, err := t.findBackend(route)
+	if err != nil {
+		return 0, err
+	}
+	return weight(backend)
+}
+
+// SetWeight updates the weight of the backend. The update is rejected when the
+// route was changed since it was read, in which case it is read and updated again.
+func (t *Target) SetWeight(ctx context.Context, value int64) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		route, err := t.fetch(ctx)
+		if err != nil {
+			return err
+		}
+		rules, backend, err := t.findBackend(route)
+		if err != nil {
+			return err
+		}
+
+		// the rules are a copy of the object, so they are written back
+		backend["weight"] = value
+		if err := unstructured.SetNestedSlice(route.Object, rules, "spec", "rules"); err != nil {
+			return err
+		}
+		return t.client.Update(ctx, route)
+	})
+}
+
+func (t *Target) WeightRange() (int64, int64) {
+	return minWeight, maxWeight
+}
+
+func (t *Target) fetch(ctx context.Context) (*unstructured.Unstructured, error) {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(t.gvk)
+	if err := t.client.Get(ctx, t.key, route); err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", t.gvk.Kind, t.key, err)
+	}
+	return route, nil
+}
+
+// findBackend returns the rules of the route and the backendRef of the target
+// within them
+func (t *Target) findBackend(route *unstructured.Unstructured) ([]interface{}, map[string]interface{}, error) {
+	rules, _, err := unstructured.NestedSlice(route.Object, "spec", "rules")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid rules of %s %s: %w", t.gvk.Kind, t.key, err)
+	}
+
+	rule, err := t.findRule(rules)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refs, _, err := unstructured.NestedSlice(rule, "backendRefs")
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid backendRefs of %s %s: %w", t.gvk.Kind, t.key, err)
+	}
+	for _, r := range refs {
+		ref, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, _, _ := unstructured.NestedString(ref, "name"); name == t.backend {
+			// point into the rules so that changes of the backendRef are written back
+			rule["backendRefs"] = refs
+			return rules, ref, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("backend %q not found in %s %s", t.backend, t.gvk.Kind, t.key)
+}
+
+// findRule returns the rule named ruleName, or the rule at the index
+func (t *Target) findRule(rules []interface{}) (map[string]interface{}, error) {
+	if t.ruleName != "" {
+		for _, r := range rules {
+			rule, ok := r.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if name, _, _ := unstructured.NestedString(rule, "name"); name == t.ruleName {
+				return rule, nil
+			}
+		}
+		return nil, fmt.Errorf("rule %q not found in %s %s", t.ruleName, t.gvk.Kind, t.key)
+	}
+
+	if t.rule >= len(rules) {
+		return nil, fmt.Errorf("%s %s has %d rules, rule %d not found", t.gvk.Kind, t.key, len(rules), t.rule)
+	}
+	rule, ok := rules[t.rule].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid rule %d of %s %s", t.rule, t.gvk.Kind, t.key)
+	}
+	return rule, nil
+}
+
+func weight(backend map[string]interface{}) (int64, error) {
+	switch w := backend["weight"].(type) {
+	case nil:
+		return defaultWeight, nil
+	case int64:
+		return w, nil
+	case float64:
+		return int64(w), nil
+	default:
+		return 0, fmt.Errorf("unexpected weight type %T", w)
+	}
+}
+
+func init() {
+	rebalancerv1.RegisterTarget(&Target{}, &rebalancerv1.RebalanceTarget{
+		GatewayAPI: &rebalancerv1.GatewayAPITarget{},
+	})
+}
@@ -0,0 +1,103 @@
+package gatewayapi
+
+import (
+	"context"
+	"testing"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSetWeightByRuleName(t *testing.T) {
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"rules": []interface{}{
+				map[string]interface{}{
+					"name":        "default",
+					"backendRefs": []interface{}{map[string]interface{}{"name": "stable"}},
+				},
+				map[string]interface{}{
+					"name": "canary",
+					"backendRefs": []interface{}{
+						map[string]interface{}{"name": "stable", "weight": int64(90)},
+						map[string]interface{}{"name": "canary", "weight": int64(10)},
+					},
+				},
+			},
+		},
+	}}
+	route.SetGroupVersionKind(routeGVKs[rebalancerv1.GatewayAPIGRPCRoute])
+	route.SetNamespace("default")
+	route.SetName("grpc")
+	c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).WithObjects(route).Build()
+
+	rb := rebalancerv1.Rebalance{
+		Spec: rebalancerv1.RebalanceSpec{
+			Target: rebalancerv1.RebalanceTarget{
+				GatewayAPI: &rebalancerv1.GatewayAPITarget{
+					Kind:     rebalancerv1.GatewayAPIGRPCRoute,
+					Name:     "grpc",
+					RuleName: "canary",
+					Backend:  "stable",
+				},
+			},
+		},
+	}
+	rb.Namespace = "default"
+	target, err := (&Target{}).NewClient(context.Background(), rb, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := target.SetWeight(context.Background(), 70); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := target.GetWeight(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 70 {
+		t.Errorf("GetWeight() = %v, want 70", got)
+	}
+
+	// the backend of the same name in the other rule is left untouched
+	rb.Spec.Target.GatewayAPI.RuleName = "default"
+	other, err := (&Target{}).NewClient(context.Background(), rb, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := other.GetWeight(context.Background()); err != nil || got != defaultWeight {
+		t.Errorf("GetWeight() = %v, %v, want %v", got, err, defaultWeight)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	rule := int32(1)
+	tests := []struct {
+		name    string
+		spec    rebalancerv1.GatewayAPITarget
+		wantErr bool
+	}{
+		{"valid", rebalancerv1.GatewayAPITarget{Name: "route", Backend: "canary"}, false},
+		{"missing backend", rebalancerv1.GatewayAPITarget{Name: "route"}, true},
+		{"unsupported kind", rebalancerv1.GatewayAPITarget{Kind: "TCPRoute", Name: "route", Backend: "canary"}, true},
+		{"rule and rule name", rebalancerv1.GatewayAPITarget{Name: "route", Rule: &rule, RuleName: "canary", Backend: "canary"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := tt.spec
+			rb := rebalancerv1.Rebalance{
+				Spec: rebalancerv1.RebalanceSpec{
+					Target: rebalancerv1.RebalanceTarget{GatewayAPI: &spec},
+				},
+			}
+			if err := (&Target{}).Validate(rb); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
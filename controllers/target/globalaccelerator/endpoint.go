@@ -0,0 +1,78 @@
+package globalaccelerator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/globalaccelerator"
+	"github.com/aws/aws-sdk-go-v2/service/globalaccelerator/types"
+)
+
+// weights of the endpoints of an endpoint group must be within this range
+const (
+	minWeight = 0
+	maxWeight = 255
+)
+
+// EndpointTarget manages the weight of an endpoint of an endpoint group
+type EndpointTarget struct {
+	endpointGroupARN string
+	endpointID       string
+	client           *globalaccelerator.Client
+}
+
+func (t *EndpointTarget) GetWeight(ctx context.Context) (int64, error) {
+	group, err := describeEndpointGroup(ctx, t.client, t.endpointGroupARN)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range group.EndpointDescriptions {
+		if aws.ToString(e.EndpointId) == t.endpointID {
+			return int64(aws.ToInt32(e.Weight)), nil
+		}
+	}
+	return 0, t.notFound()
+}
+
+// SetWeight updates the weight of the endpoint. The endpoint group replaces its
+// endpoints with the configurations of the update, so every endpoint is sent
+// as it is except for the weight of the endpoint.
+func (t *EndpointTarget) SetWeight(ctx context.Context, value int64) error {
+	group, err := describeEndpointGroup(ctx, t.client, t.endpointGroupARN)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	configs := make([]types.EndpointConfiguration, 0, len(group.EndpointDescriptions))
+	for _, e := range group.EndpointDescriptions {
+		config := types.EndpointConfiguration{
+			EndpointId:                  e.EndpointId,
+			Weight:                      e.Weight,
+			ClientIPPreservationEnabled: e.ClientIPPreservationEnabled,
+		}
+		if aws.ToString(e.EndpointId) == t.endpointID {
+			config.Weight = aws.Int32(int32(value))
+			found = true
+		}
+		configs = append(configs, config)
+	}
+	if !found {
+		return t.notFound()
+	}
+
+	_, err = t.client.UpdateEndpointGroup(ctx, &globalaccelerator.UpdateEndpointGroupInput{
+		EndpointGroupArn:       aws.String(t.endpointGroupARN),
+		EndpointConfigurations: configs,
+	})
+	return err
+}
+
+func (t *EndpointTarget) WeightRange() (int64, int64) {
+	return minWeight, maxWeight
+}
+
+func (t *EndpointTarget) notFound() error {
+	return fmt.Errorf("endpoint %s not found in endpoint group %s", t.endpointID, t.endpointGroupARN)
+}
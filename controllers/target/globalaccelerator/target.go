@@ -0,0 +1,114 @@
+package globalaccelerator
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"git.pepabo.com/akichan/rebalancer/controllers/target/awsauth"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/globalaccelerator"
+	"github.com/aws/aws-sdk-go-v2/service/globalaccelerator/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Global Accelerator is a global service whose API is served only in this region
+const region = "us-west-2"
+
+// the traffic dial of an endpoint group is a percentage
+const (
+	minTrafficDial = 0
+	maxTrafficDial = 100
+)
+
+// Target manages the traffic dial of an endpoint group
+type Target struct {
+	endpointGroupARN string
+	client           *globalaccelerator.Client
+}
+
+func (t *Target) NewClient(ctx context.Context, r rebalancerv1.Rebalance, c client.Client) (rebalancerv1.TargetClient, error) {
+	spec := r.Spec.Target.GlobalAccelerator
+
+	cfg, err := awsauth.LoadConfig(ctx, r, c, spec.Auth, region)
+	if err != nil {
+		return nil, err
+	}
+	gaClient := globalaccelerator.NewFromConfig(cfg)
+
+	if spec.Mode == rebalancerv1.GlobalAcceleratorEndpointWeight {
+		return &EndpointTarget{
+			endpointGroupARN: spec.EndpointGroupARN,
+			endpointID:       spec.EndpointID,
+			client:           gaClient,
+		}, nil
+	}
+	return &Target{
+		endpointGroupARN: spec.EndpointGroupARN,
+		client:           gaClient,
+	}, nil
+}
+
+func (t *Target) Validate(r rebalancerv1.Rebalance) error {
+	spec := r.Spec.Target.GlobalAccelerator
+	var errs []error
+
+	if spec.EndpointGroupARN == "" {
+		errs = append(errs, fmt.Errorf("endpointGroupARN must not be empty"))
+	}
+	switch spec.Mode {
+	case "", rebalancerv1.GlobalAcceleratorTrafficDial:
+		if spec.EndpointID != "" {
+			errs = append(errs, fmt.Errorf("endpointID is used only in the %s mode", rebalancerv1.GlobalAcceleratorEndpointWeight))
+		}
+	case rebalancerv1.GlobalAcceleratorEndpointWeight:
+		if spec.EndpointID == "" {
+			errs = append(errs, fmt.Errorf("endpointID must not be empty in the %s mode", spec.Mode))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown mode %q", spec.Mode))
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (t *Target) GetWeight(ctx context.Context) (int64, error) {
+	group, err := describeEndpointGroup(ctx, t.client, t.endpointGroupARN)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(float64(aws.ToFloat32(group.TrafficDialPercentage)))), nil
+}
+
+func (t *Target) SetWeight(ctx context.Context, value int64) error {
+	_, err := t.client.UpdateEndpointGroup(ctx, &globalaccelerator.UpdateEndpointGroupInput{
+		EndpointGroupArn:      aws.String(t.endpointGroupARN),
+		TrafficDialPercentage: aws.Float32(float32(value)),
+	})
+	return err
+}
+
+func (t *Target) WeightRange() (int64, int64) {
+	return minTrafficDial, maxTrafficDial
+}
+
+func describeEndpointGroup(ctx context.Context, c *globalaccelerator.Client, arn string) (*types.EndpointGroup, error) {
+	out, err := c.DescribeEndpointGroup(ctx, &globalaccelerator.DescribeEndpointGroupInput{
+		EndpointGroupArn: aws.String(arn),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if out.EndpointGroup == nil {
+		return nil, fmt.Errorf("endpoint group %s not found", arn)
+	}
+	return out.EndpointGroup, nil
+}
+
+func init() {
+	rebalancerv1.RegisterTarget(&Target{}, &rebalancerv1.RebalanceTarget{
+		GlobalAccelerator: &rebalancerv1.GlobalAcceleratorTarget{},
+	})
+}
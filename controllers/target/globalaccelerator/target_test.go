@@ -0,0 +1,130 @@
+package globalaccelerator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/globalaccelerator"
+)
+
+const endpointGroupARN = "arn:aws:globalaccelerator::123456789012:accelerator/1/listener/2/endpoint-group/3"
+
+type endpoint struct {
+	EndpointId                  string
+	Weight                      int32
+	ClientIPPreservationEnabled bool
+}
+
+// stub serves DescribeEndpointGroup and UpdateEndpointGroup of an endpoint group
+type stub struct {
+	mu          sync.Mutex
+	trafficDial float32
+	endpoints   []endpoint
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var in struct {
+		EndpointGroupArn       string
+		TrafficDialPercentage  *float32
+		EndpointConfigurations []endpoint
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.EndpointGroupArn != endpointGroupARN {
+		http.Error(w, `{"__type":"EndpointGroupNotFoundException"}`, http.StatusBadRequest)
+		return
+	}
+
+	switch op := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "GlobalAccelerator_V20180706."); op {
+	case "DescribeEndpointGroup":
+	case "UpdateEndpointGroup":
+		if in.TrafficDialPercentage != nil {
+			s.trafficDial = *in.TrafficDialPercentage
+		}
+		if in.EndpointConfigurations != nil {
+			s.endpoints = in.EndpointConfigurations
+		}
+	default:
+		http.Error(w, `{"__type":"InvalidAction"}`, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"EndpointGroup": map[string]interface{}{
+			"EndpointGroupArn":      endpointGroupARN,
+			"TrafficDialPercentage": s.trafficDial,
+			"EndpointDescriptions":  s.endpoints,
+		},
+	})
+}
+
+func newClient(url string) *globalaccelerator.Client {
+	return globalaccelerator.New(globalaccelerator.Options{
+		Region:           region,
+		Credentials:      credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		EndpointResolver: globalaccelerator.EndpointResolverFromURL(url),
+	})
+}
+
+func TestTrafficDial(t *testing.T) {
+	s := &stub{trafficDial: 100}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	target := &Target{endpointGroupARN: endpointGroupARN, client: newClient(srv.URL)}
+	ctx := context.Background()
+
+	if err := target.SetWeight(ctx, 25); err != nil {
+		t.Fatal(err)
+	}
+	got, err := target.GetWeight(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 25 {
+		t.Errorf("GetWeight() = %v, want 25", got)
+	}
+}
+
+func TestEndpointWeight(t *testing.T) {
+	s := &stub{
+		trafficDial: 100,
+		endpoints: []endpoint{
+			{EndpointId: "alb-blue", Weight: 128, ClientIPPreservationEnabled: true},
+			{EndpointId: "alb-green", Weight: 128},
+		},
+	}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	target := &EndpointTarget{endpointGroupARN: endpointGroupARN, endpointID: "alb-green", client: newClient(srv.URL)}
+	ctx := context.Background()
+
+	if err := target.SetWeight(ctx, 32); err != nil {
+		t.Fatal(err)
+	}
+	got, err := target.GetWeight(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 32 {
+		t.Errorf("GetWeight() = %v, want 32", got)
+	}
+
+	// the other endpoints are kept as they are
+	want := endpoint{EndpointId: "alb-blue", Weight: 128, ClientIPPreservationEnabled: true}
+	if len(s.endpoints) != 2 || s.endpoints[0] != want {
+		t.Errorf("endpoints = %+v, want %+v to be kept", s.endpoints, want)
+	}
+
+	target.endpointID = "unknown"
+	if _, err := target.GetWeight(ctx); err == nil {
+		t.Errorf("GetWeight() must fail when the endpoint is not in the endpoint group")
+	}
+}
@@ -1,5 +1,10 @@
 package register
 
 import (
+	_ "git.pepabo.com/akichan/rebalancer/controllers/target/alb"
+	_ "git.pepabo.com/akichan/rebalancer/controllers/target/clouddns"
+	_ "git.pepabo.com/akichan/rebalancer/controllers/target/cloudflare"
+	_ "git.pepabo.com/akichan/rebalancer/controllers/target/gatewayapi"
+	_ "git.pepabo.com/akichan/rebalancer/controllers/target/globalaccelerator"
 	_ "git.pepabo.com/akichan/rebalancer/controllers/target/route53"
 )
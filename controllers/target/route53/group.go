@@ -0,0 +1,136 @@
+package route53
+
+import (
+	"context"
+	"fmt"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+// GroupTarget manages every weighted record of a name and type as one group
+type GroupTarget struct {
+	hostedZoneId string
+	recordName   string
+	recordType   types.RRType
+	group        rebalancerv1.WeightGroup
+	client       *route53.Client
+}
+
+func (t *GroupTarget) Distribute(value int64) (map[string]int64, error) {
+	return t.group.Distribute(value)
+}
+
+func (t *GroupTarget) GetWeight(ctx context.Context) (int64, error) {
+	tracked, err := t.group.TrackedMember()
+	if err != nil {
+		return 0, err
+	}
+	weights, err := t.GetWeights(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return weights[tracked.Identifier], nil
+}
+
+func (t *GroupTarget) SetWeight(ctx context.Context, value int64) error {
+	weights, err := t.Distribute(value)
+	if err != nil {
+		return err
+	}
+	return t.SetWeights(ctx, weights)
+}
+
+// WeightRange returns the range of the tracked member, which cannot exceed the total
+func (t *GroupTarget) WeightRange() (int64, int64) {
+	return minWeight, t.group.Total
+}
+
+func (t *GroupTarget) GetWeights(ctx context.Context) (map[string]int64, error) {
+	rrs, err := t.fetchResourceRecordSets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[string]int64, len(t.group.Members))
+	for _, m := range t.group.Members {
+		rr := rrs[m.Identifier]
+		if rr.Weight == nil {
+			return nil, fmt.Errorf("resource record %q is not weighted", m.Identifier)
+		}
+		weights[m.Identifier] = *rr.Weight
+	}
+	return weights, nil
+}
+
+func (t *GroupTarget) SetWeights(ctx context.Context, weights map[string]int64) error {
+	rrs, err := t.fetchResourceRecordSets(ctx)
+	if err != nil {
+		return err
+	}
+
+	changes := make([]types.Change, 0, len(weights))
+	for _, m := range t.group.Members {
+		w, ok := weights[m.Identifier]
+		if !ok {
+			continue
+		}
+		rr := rrs[m.Identifier]
+		rr.Weight = aws.Int64(w)
+		changes = append(changes, types.Change{Action: types.ChangeActionUpsert, ResourceRecordSet: &rr})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	_, err = t.client.ChangeResourceRecordSets(ctx, &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: aws.String(t.hostedZoneId),
+		ChangeBatch:  &types.ChangeBatch{Changes: changes},
+	})
+	return err
+}
+
+// fetchResourceRecordSets returns the record of every member keyed by the set identifier
+func (t *GroupTarget) fetchResourceRecordSets(ctx context.Context) (map[string]types.ResourceRecordSet, error) {
+	rname := fqdn(t.recordName)
+	rrs := make(map[string]types.ResourceRecordSet, len(t.group.Members))
+	input := &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    aws.String(t.hostedZoneId),
+		StartRecordName: aws.String(t.recordName),
+		StartRecordType: t.recordType,
+	}
+
+	for {
+		out, err := t.client.ListResourceRecordSets(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		done := !out.IsTruncated
+		for _, rr := range out.ResourceRecordSets {
+			if *rr.Name != rname || rr.Type != t.recordType {
+				// records are sorted by name and type, so the rest is out of the group
+				done = true
+				break
+			}
+			if rr.SetIdentifier != nil {
+				rrs[*rr.SetIdentifier] = rr
+			}
+		}
+		if done {
+			break
+		}
+		input.StartRecordName = out.NextRecordName
+		input.StartRecordType = out.NextRecordType
+		input.StartRecordIdentifier = out.NextRecordIdentifier
+	}
+
+	for _, m := range t.group.Members {
+		if _, ok := rrs[m.Identifier]; !ok {
+			return nil, fmt.Errorf("resource record %q not found", m.Identifier)
+		}
+	}
+	return rrs, nil
+}
@@ -6,12 +6,11 @@ import (
 	"strings"
 
 	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"git.pepabo.com/akichan/rebalancer/controllers/target/awsauth"
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/route53"
 	"github.com/aws/aws-sdk-go-v2/service/route53/types"
-	v1 "k8s.io/api/core/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -25,27 +24,26 @@ type Target struct {
 }
 
 func (t *Target) NewClient(ctx context.Context, r rebalancerv1.Rebalance, c client.Client) (rebalancerv1.TargetClient, error) {
-	var optFns []func(*config.LoadOptions) error
-
-	// secret ref option
-	if r.Spec.Target.Route53.Auth.SecretRef != nil {
-		cred, err := credFromSecretRef(ctx, &r, c)
-		if err != nil {
-			return nil, err
-		}
-		optFns = append(optFns, config.WithCredentialsProvider(cred))
+	region := r.Spec.Target.Route53.Region
+	if region == "" {
+		region = rebalancerv1.DefaultRoute53Region
 	}
-
-	// region option
-	if region := r.Spec.Target.Route53.Region; region == "" {
-		return nil, fmt.Errorf("route53 target require region")
-	} else {
-		optFns = append(optFns, config.WithRegion(region))
+	cfg, err := awsauth.LoadConfig(ctx, r, c, r.Spec.Target.Route53.Auth, region)
+	if err != nil {
+		return nil, err
 	}
 
-	cfg, err := config.LoadDefaultConfig(ctx, optFns...)
-	if err != nil {
-		return nil, fmt.Errorf("load config error: %w", err)
+	if group := r.Spec.Target.Route53.Group; group != nil {
+		if _, err := group.TrackedMember(); err != nil {
+			return nil, err
+		}
+		return &GroupTarget{
+			hostedZoneId: r.Spec.Target.Route53.HostedZoneID,
+			recordName:   r.Spec.Target.Route53.Resource.Name,
+			recordType:   r.Spec.Target.Route53.Resource.Type,
+			group:        *group,
+			client:       route53.NewFromConfig(cfg),
+		}, nil
 	}
 
 	return &Target{
@@ -57,51 +55,43 @@ func (t *Target) NewClient(ctx context.Context, r rebalancerv1.Rebalance, c clie
 	}, nil
 }
 
-func credFromSecretRef(ctx context.Context, r *rebalancerv1.Rebalance, c client.Client) (credentials.StaticCredentialsProvider, error) {
-	secRef := r.Spec.Target.Route53.Auth.SecretRef
+// weights of Route53 weighted records must be within this range
+const (
+	minWeight = 0
+	maxWeight = 255
+)
 
-	// get access key id from secret
-	var ns string
-	if secRef.AccessKeyID.Namespace != nil {
-		ns = *secRef.AccessKeyID.Namespace
-	} else {
-		ns = r.Namespace
-	}
-	ke := client.ObjectKey{
-		Name:      secRef.AccessKeyID.Name,
-		Namespace: ns,
-	}
-	akSecret := v1.Secret{}
-	err := c.Get(ctx, ke, &akSecret)
-	if err != nil {
-		return credentials.StaticCredentialsProvider{}, fmt.Errorf("failed to get access key id: %w", err)
-	}
+func (t *Target) Validate(r rebalancerv1.Rebalance) error {
+	spec := r.Spec.Target.Route53
+	var errs []error
 
-	// get secret access key from secret
-	if secRef.SecretAccessKey.Namespace != nil {
-		ns = *secRef.SecretAccessKey.Namespace
-	} else {
-		ns = r.Namespace
+	if spec.HostedZoneID == "" {
+		errs = append(errs, fmt.Errorf("hostedZoneID must not be empty"))
 	}
-	ke = client.ObjectKey{
-		Name:      secRef.SecretAccessKey.Name,
-		Namespace: ns,
+	if spec.Resource.Name == "" {
+		errs = append(errs, fmt.Errorf("resource name must not be empty"))
 	}
-	sakSecret := v1.Secret{}
-	err = c.Get(ctx, ke, &sakSecret)
-	if err != nil {
-		return credentials.StaticCredentialsProvider{}, fmt.Errorf("failed to get secret access key: %w", err)
+	if spec.Group == nil && spec.Resource.Identifier == "" {
+		errs = append(errs, fmt.Errorf("resource identifier must not be empty"))
 	}
 
-	ak := string(akSecret.Data[secRef.AccessKeyID.Key])
-	sak := string(sakSecret.Data[secRef.SecretAccessKey.Key])
-	if ak == "" {
-		return credentials.StaticCredentialsProvider{}, fmt.Errorf("missing access key id")
-	}
-	if sak == "" {
-		return credentials.StaticCredentialsProvider{}, fmt.Errorf("missing secret access key")
+	if group := spec.Group; group != nil {
+		if _, err := group.TrackedMember(); err != nil {
+			errs = append(errs, err)
+		}
+		if group.Total < minWeight || group.Total > maxWeight {
+			errs = append(errs, fmt.Errorf("group total must be between %d and %d: %d", minWeight, maxWeight, group.Total))
+		}
+		ids := make(map[string]bool, len(group.Members))
+		for _, m := range group.Members {
+			if ids[m.Identifier] {
+				errs = append(errs, fmt.Errorf("duplicate group member %q", m.Identifier))
+			}
+			ids[m.Identifier] = true
+		}
 	}
-	return credentials.NewStaticCredentialsProvider(ak, sak, ""), nil
+
+	return utilerrors.NewAggregate(errs)
 }
 
 func (t *Target) GetWeight(ctx context.Context) (int64, error) {
@@ -112,6 +102,10 @@ func (t *Target) GetWeight(ctx context.Context) (int64, error) {
 	return *t.rr.Weight, nil
 }
 
+func (t *Target) WeightRange() (int64, int64) {
+	return minWeight, maxWeight
+}
+
 func (p *Target) SetWeight(ctx context.Context, value int64) error {
 	err := p.fetchResourceRecordSets(ctx)
 	if err != nil {
@@ -147,10 +141,7 @@ func (t *Target) fetchResourceRecordSets(ctx context.Context) error {
 		return err
 	}
 
-	rname := t.recordName
-	if !strings.HasSuffix(".", rname) {
-		rname = rname + "."
-	}
+	rname := fqdn(t.recordName)
 	for _, rr := range out.ResourceRecordSets {
 		if *rr.Name == rname && *rr.SetIdentifier == t.recordId {
 			t.rr = rr
@@ -160,6 +151,14 @@ func (t *Target) fetchResourceRecordSets(ctx context.Context) error {
 	return fmt.Errorf("resource record not found")
 }
 
+// fqdn returns the record name with the trailing dot used by Route53
+func fqdn(name string) string {
+	if !strings.HasSuffix(name, ".") {
+		return name + "."
+	}
+	return name
+}
+
 func init() {
 	rebalancerv1.RegisterTarget(&Target{}, &rebalancerv1.RebalanceTarget{
 		Route53: &rebalancerv1.Route53Target{},
@@ -0,0 +1,211 @@
+package route53
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+)
+
+const (
+	hostedZoneID = "Z1"
+	namespace    = "https://route53.amazonaws.com/doc/2013-04-01/"
+)
+
+type record struct {
+	Name          string
+	Type          string
+	SetIdentifier string
+	Weight        int64
+	TTL           int64
+}
+
+// stub serves ListResourceRecordSets in pages of one record and ChangeResourceRecordSets
+type stub struct {
+	mu sync.Mutex
+	// records are sorted by name, type and set identifier like Route53 does
+	records []record
+	lists   int
+	// changes are the batches of the ChangeResourceRecordSets requests
+	changes [][]record
+}
+
+func (s *stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !strings.HasPrefix(r.URL.Path, "/2013-04-01/hostedzone/"+hostedZoneID+"/rrset") {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `<ErrorResponse xmlns="%s"><Error><Type>Sender</Type><Code>NoSuchHostedZone</Code><Message>not found</Message></Error></ErrorResponse>`, namespace)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.lists++
+		s.list(w, r)
+	case http.MethodPost:
+		var req struct {
+			Changes []struct {
+				Action            string
+				ResourceRecordSet record
+			} `xml:"ChangeBatch>Changes>Change"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var batch []record
+		for _, c := range req.Changes {
+			batch = append(batch, c.ResourceRecordSet)
+			for i := range s.records {
+				rr := &s.records[i]
+				if rr.Name == c.ResourceRecordSet.Name && rr.Type == c.ResourceRecordSet.Type && rr.SetIdentifier == c.ResourceRecordSet.SetIdentifier {
+					*rr = c.ResourceRecordSet
+				}
+			}
+		}
+		s.changes = append(s.changes, batch)
+		fmt.Fprintf(w, `<ChangeResourceRecordSetsResponse xmlns="%s"><ChangeInfo><Id>/change/C1</Id><Status>PENDING</Status>`+
+			`<SubmittedAt>2022-12-20T12:00:00Z</SubmittedAt></ChangeInfo></ChangeResourceRecordSetsResponse>`, namespace)
+	}
+}
+
+func (s *stub) list(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	start := len(s.records)
+	for i, rr := range s.records {
+		if rr.Name == fqdn(q.Get("name")) && rr.Type == q.Get("type") &&
+			(q.Get("identifier") == "" || rr.SetIdentifier == q.Get("identifier")) {
+			start = i
+			break
+		}
+	}
+
+	var b strings.Builder
+	if start < len(s.records) {
+		rr := s.records[start]
+		fmt.Fprintf(&b, "<ResourceRecordSet><Name>%s</Name><Type>%s</Type><SetIdentifier>%s</SetIdentifier><Weight>%d</Weight><TTL>%d</TTL>"+
+			"<ResourceRecords><ResourceRecord><Value>192.0.2.1</Value></ResourceRecord></ResourceRecords></ResourceRecordSet>",
+			rr.Name, rr.Type, rr.SetIdentifier, rr.Weight, rr.TTL)
+	}
+	next := ""
+	if start+1 < len(s.records) {
+		rr := s.records[start+1]
+		next = fmt.Sprintf("<IsTruncated>true</IsTruncated><NextRecordName>%s</NextRecordName><NextRecordType>%s</NextRecordType>"+
+			"<NextRecordIdentifier>%s</NextRecordIdentifier>", rr.Name, rr.Type, rr.SetIdentifier)
+	} else {
+		next = "<IsTruncated>false</IsTruncated>"
+	}
+	fmt.Fprintf(w, `<ListResourceRecordSetsResponse xmlns="%s"><ResourceRecordSets>%s</ResourceRecordSets>%s<MaxItems>1</MaxItems></ListResourceRecordSetsResponse>`,
+		namespace, b.String(), next)
+}
+
+func newClient(url string) *route53.Client {
+	return route53.New(route53.Options{
+		Region:           rebalancerv1.DefaultRoute53Region,
+		Credentials:      credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		EndpointResolver: route53.EndpointResolverFromURL(url),
+	})
+}
+
+func newStub() *stub {
+	return &stub{records: []record{
+		{Name: "api.example.com.", Type: "A", SetIdentifier: "api", Weight: 1, TTL: 60},
+		{Name: "www.example.com.", Type: "A", SetIdentifier: "blue", Weight: 200, TTL: 60},
+		{Name: "www.example.com.", Type: "A", SetIdentifier: "green", Weight: 50, TTL: 60},
+		{Name: "www.example.com.", Type: "A", SetIdentifier: "red", Weight: 5, TTL: 60},
+		{Name: "www.example.com.", Type: "AAAA", SetIdentifier: "blue", Weight: 1, TTL: 60},
+		{Name: "zzz.example.com.", Type: "A", SetIdentifier: "zzz", Weight: 1, TTL: 60},
+	}}
+}
+
+func TestTarget(t *testing.T) {
+	s := newStub()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	target := &Target{
+		hostedZoneId: hostedZoneID,
+		recordName:   "www.example.com",
+		recordId:     "green",
+		recordType:   types.RRTypeA,
+		client:       newClient(srv.URL),
+	}
+	ctx := context.Background()
+
+	if got, err := target.GetWeight(ctx); err != nil || got != 50 {
+		t.Errorf("GetWeight() = %v, %v, want 50", got, err)
+	}
+	if err := target.SetWeight(ctx, 30); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := target.GetWeight(ctx); err != nil || got != 30 {
+		t.Errorf("GetWeight() = %v, %v, want 30", got, err)
+	}
+}
+
+func TestGroupTarget(t *testing.T) {
+	s := newStub()
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	target := &GroupTarget{
+		hostedZoneId: hostedZoneID,
+		recordName:   "www.example.com",
+		recordType:   types.RRTypeA,
+		group: rebalancerv1.WeightGroup{
+			Total: 255,
+			Members: []rebalancerv1.WeightGroupMember{
+				{Identifier: "green", Tracked: true},
+				{Identifier: "blue", Ratio: 2},
+				{Identifier: "red", Ratio: 1},
+			},
+		},
+		client: newClient(srv.URL),
+	}
+	ctx := context.Background()
+
+	weights, err := target.GetWeights(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if weights["green"] != 50 || weights["blue"] != 200 || weights["red"] != 5 {
+		t.Errorf("GetWeights() = %v", weights)
+	}
+	// the pages of the group and the first page of the next record type are listed
+	if s.lists != 4 {
+		t.Errorf("ListResourceRecordSets was called %d times, want 4", s.lists)
+	}
+
+	if err := target.SetWeight(ctx, 75); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.changes) != 1 || len(s.changes[0]) != 3 {
+		t.Fatalf("changes = %v, want a single batch of every member", s.changes)
+	}
+	want := map[string]int64{"green": 75, "blue": 120, "red": 60}
+	for _, rr := range s.changes[0] {
+		if rr.Weight != want[rr.SetIdentifier] {
+			t.Errorf("weight of %s = %d, want %d", rr.SetIdentifier, rr.Weight, want[rr.SetIdentifier])
+		}
+		if rr.TTL != 60 {
+			t.Errorf("ttl of %s = %d, want 60 to be kept", rr.SetIdentifier, rr.TTL)
+		}
+	}
+	if got, err := target.GetWeight(ctx); err != nil || got != 75 {
+		t.Errorf("GetWeight() = %v, %v, want 75", got, err)
+	}
+
+	target.group.Members = append(target.group.Members, rebalancerv1.WeightGroupMember{Identifier: "yellow", Ratio: 1})
+	if _, err := target.GetWeights(ctx); err == nil {
+		t.Errorf("GetWeights() must fail when a member has no record")
+	}
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+var _ = Describe("Gateway API target", func() {
+	ctx := context.Background()
+	routeGVKs := map[string]schema.GroupVersionKind{
+		rebalancerv1.GatewayAPIHTTPRoute: {Group: "gateway.networking.k8s.io", Version: "v1beta1", Kind: "HTTPRoute"},
+		rebalancerv1.GatewayAPIGRPCRoute: {Group: "gateway.networking.k8s.io", Version: "v1alpha2", Kind: "GRPCRoute"},
+	}
+
+	for kind, gvk := range routeGVKs {
+		kind, gvk := kind, gvk
+
+		Context(kind, func() {
+			newRoute := func(name string) *unstructured.Unstructured {
+				route := &unstructured.Unstructured{Object: map[string]interface{}{
+					"spec": map[string]interface{}{
+						"parentRefs": []interface{}{map[string]interface{}{"name": "gateway"}},
+						"rules": []interface{}{
+							map[string]interface{}{
+								"backendRefs": []interface{}{
+									map[string]interface{}{"name": "mirror", "port": int64(80)},
+								},
+							},
+							map[string]interface{}{
+								"backendRefs": []interface{}{
+									map[string]interface{}{"name": "stable", "port": int64(80), "weight": int64(90)},
+									map[string]interface{}{"name": "canary", "port": int64(80), "weight": int64(10)},
+								},
+							},
+						},
+					},
+				}}
+				route.SetGroupVersionKind(gvk)
+				route.SetNamespace("default")
+				route.SetName(name)
+				return route
+			}
+
+			newTarget := func(route string, rule int32, backend string) rebalancerv1.TargetClient {
+				rb := rebalancerv1.Rebalance{
+					Spec: rebalancerv1.RebalanceSpec{
+						Target: rebalancerv1.RebalanceTarget{
+							GatewayAPI: &rebalancerv1.GatewayAPITarget{Kind: kind, Name: route, Rule: &rule, Backend: backend},
+						},
+					},
+				}
+				rb.Namespace = "default"
+				t, err := rebalancerv1.GetTarget(rb)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(t.Validate(rb)).To(Succeed())
+				c, err := t.NewClient(ctx, rb, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+				return c
+			}
+
+			backendWeights := func(name string) []interface{} {
+				route := &unstructured.Unstructured{}
+				route.SetGroupVersionKind(gvk)
+				Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, route)).To(Succeed())
+				refs, _, err := unstructured.NestedSlice(route.Object, "spec", "rules")
+				Expect(err).NotTo(HaveOccurred())
+				var weights []interface{}
+				for _, r := range refs[1].(map[string]interface{})["backendRefs"].([]interface{}) {
+					weights = append(weights, r.(map[string]interface{})["weight"])
+				}
+				return weights
+			}
+
+			It("updates the weight of the backend", func() {
+				Expect(k8sClient.Create(ctx, newRoute("weighted"))).To(Succeed())
+				target := newTarget("weighted", 1, "canary")
+
+				Expect(target.GetWeight(ctx)).To(Equal(int64(10)))
+				Expect(target.SetWeight(ctx, 50)).To(Succeed())
+				Expect(target.GetWeight(ctx)).To(Equal(int64(50)))
+				Expect(backendWeights("weighted")).To(Equal([]interface{}{int64(90), int64(50)}))
+			})
+
+			It("reads the default weight of a backend without weight", func() {
+				Expect(k8sClient.Create(ctx, newRoute("unweighted"))).To(Succeed())
+				target := newTarget("unweighted", 0, "mirror")
+
+				Expect(target.GetWeight(ctx)).To(Equal(int64(1)))
+			})
+
+			It("fails when the backend is not found", func() {
+				Expect(k8sClient.Create(ctx, newRoute("missing"))).To(Succeed())
+				target := newTarget("missing", 1, "unknown")
+
+				_, err := target.GetWeight(ctx)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	}
+})
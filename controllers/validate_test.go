@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	rebalancerv1 "git.pepabo.com/akichan/rebalancer/api/v1"
+)
+
+// TestValidateRebalance runs the admission checks with the registered
+// policies, targets and metrics
+func TestValidateRebalance(t *testing.T) {
+	valid := func() *rebalancerv1.Rebalance {
+		rb := &rebalancerv1.Rebalance{
+			Spec: rebalancerv1.RebalanceSpec{
+				Interval: "1m",
+				Policy: rebalancerv1.RebalancePolicy{
+					TargetTracking: &rebalancerv1.TargetTrackingPolicy{TargetValue: 100, BaseValue: 10},
+				},
+				Target: rebalancerv1.RebalanceTarget{
+					Route53: &rebalancerv1.Route53Target{
+						HostedZoneID: "Z1",
+						Resource:     rebalancerv1.Route53TargetRecord{Name: "www.example.com.", Identifier: "blue"},
+					},
+				},
+				Metrics: &rebalancerv1.RebalanceMetrics{
+					Prometheus: &rebalancerv1.PrometheusMetrics{Address: "http://prometheus:9090", Query: "up"},
+				},
+			},
+		}
+		rb.Namespace = "default"
+		return rb
+	}
+
+	tests := []struct {
+		name   string
+		modify func(rb *rebalancerv1.Rebalance)
+		// wantErrs are the substrings of the errors, in order
+		wantErrs []string
+	}{
+		{"valid", func(rb *rebalancerv1.Rebalance) {}, nil},
+		{
+			"unparseable interval",
+			func(rb *rebalancerv1.Rebalance) { rb.Spec.Interval = "soon" },
+			[]string{"invalid interval"},
+		},
+		{
+			"negative interval",
+			func(rb *rebalancerv1.Rebalance) { rb.Spec.Interval = "-1m" },
+			[]string{"interval must be positive"},
+		},
+		{
+			"relative prometheus address",
+			func(rb *rebalancerv1.Rebalance) { rb.Spec.Metrics.Prometheus.Address = "/api/v1" },
+			[]string{"address must be an absolute url"},
+		},
+		{
+			"prometheus address without host",
+			func(rb *rebalancerv1.Rebalance) { rb.Spec.Metrics.Prometheus.Address = "prometheus:9090" },
+			[]string{"address must be an absolute url"},
+		},
+		{
+			"unparseable prometheus address",
+			func(rb *rebalancerv1.Rebalance) { rb.Spec.Metrics.Prometheus.Address = "http://[::1" },
+			[]string{"failed to parse address"},
+		},
+		{
+			"route53 group total over 255",
+			func(rb *rebalancerv1.Rebalance) {
+				rb.Spec.Target.Route53.Group = &rebalancerv1.WeightGroup{
+					Total: 300,
+					Members: []rebalancerv1.WeightGroupMember{
+						{Identifier: "blue", Tracked: true},
+						{Identifier: "green", Ratio: 1},
+					},
+				}
+			},
+			[]string{"group total must be between 0 and 255"},
+		},
+		{
+			"unknown policy",
+			func(rb *rebalancerv1.Rebalance) { rb.Spec.Policy = rebalancerv1.RebalancePolicy{} },
+			[]string{"policy"},
+		},
+		{
+			"unknown target",
+			func(rb *rebalancerv1.Rebalance) { rb.Spec.Target = rebalancerv1.RebalanceTarget{} },
+			[]string{"target must only have exactly one specified"},
+		},
+		{
+			"unknown metrics",
+			func(rb *rebalancerv1.Rebalance) { rb.Spec.Metrics = &rebalancerv1.RebalanceMetrics{} },
+			[]string{"metrics"},
+		},
+		{
+			"errors are collected together",
+			func(rb *rebalancerv1.Rebalance) {
+				rb.Spec.Interval = "soon"
+				rb.Spec.Target = rebalancerv1.RebalanceTarget{}
+				rb.Spec.Metrics.Prometheus.Address = "/api/v1"
+				rb.Spec.Metrics.Prometheus.Query = ""
+			},
+			[]string{"invalid interval", "target must only have exactly one specified", "address must be an absolute url"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rb := valid()
+			tt.modify(rb)
+
+			for _, err := range []error{rb.ValidateCreate(), rb.ValidateUpdate(valid())} {
+				if len(tt.wantErrs) == 0 {
+					if err != nil {
+						t.Errorf("unexpected error: %v", err)
+					}
+					continue
+				}
+				agg, ok := err.(utilerrors.Aggregate)
+				if !ok {
+					t.Fatalf("error = %v, want an aggregate of %v", err, tt.wantErrs)
+				}
+				if len(agg.Errors()) != len(tt.wantErrs) {
+					t.Errorf("errors = %v, want %d errors", agg.Errors(), len(tt.wantErrs))
+					continue
+				}
+				for i, want := range tt.wantErrs {
+					if got := agg.Errors()[i].Error(); !strings.Contains(got, want) {
+						t.Errorf("errors[%d] = %q, want to contain %q", i, got, want)
+					}
+				}
+			}
+		})
+	}
+}